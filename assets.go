@@ -0,0 +1,8 @@
+package main
+
+import "embed"
+
+// assets are the default sprites, sounds and themes built into the binary
+//
+//go:embed sprites sounds themes
+var assets embed.FS
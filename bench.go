@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	depth := flags.Int("depth", 5, "search depth for every position")
+	searchOptions := flags.String("options", "", "search options, like nonullmove,nolmr,threads=4")
+	flags.Parse(args)
+
+	options, err := parseSearchOptions(strings.Split(*searchOptions, ","))
+	if err != nil {
+		return err
+	}
+
+	total, err := chess2.Bench(options, *depth, func(fen string, result chess2.SearchResult) {
+		fmt.Printf("%-72s %-6s %9d nodes  %v\n", fen, result.Move.UCI(), result.Nodes, result.Elapsed.Round(time.Millisecond))
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Total time: %v\n", total.Elapsed.Round(time.Millisecond))
+	fmt.Printf("Nodes searched: %d\n", total.Nodes)
+	fmt.Printf("Nodes/second: %d\n", total.NPS())
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+func runEPD(args []string) error {
+	flags := flag.NewFlagSet("epd", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: chess2 epd [options] <file>")
+		flags.PrintDefaults()
+	}
+	depth := flags.Int("depth", 0, "fixed search depth, overrides -time")
+	movetime := flags.Duration("time", time.Second, "search time per position")
+	threads := flags.Int("threads", chess2.DefaultSearchOptions().Threads, "search threads")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one EPD file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, skipped, err := chess2.ReadEPD(file)
+	if err != nil {
+		return err
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(os.Stderr, "skipped %s\n", err)
+	}
+
+	options := chess2.DefaultSearchOptions()
+	options.Threads = *threads
+	options.Ponder = false
+
+	limits := chess2.SearchLimits{Time: *movetime}
+	if *depth > 0 {
+		limits = chess2.SearchLimits{Depth: *depth}
+	}
+
+	solved := 0
+	var total time.Duration
+	for _, record := range records {
+		// the result's Elapsed stops at the last completed iteration
+		start := time.Now()
+		result := chess2.Search(record.Board, options, limits)
+		elapsed := time.Since(start)
+		total += elapsed
+
+		status := "failed"
+		if record.IsSolvedBy(result.Move) {
+			status = "solved"
+			solved++
+		}
+
+		fmt.Printf(
+			"%-16s %s  %-8s depth %2d  %9d nodes  %v\n",
+			record.ID, status, record.Board.SAN(result.Move),
+			result.Depth, result.Nodes, elapsed.Round(time.Millisecond),
+		)
+	}
+
+	fmt.Printf("\nSolved %d/%d in %v\n", solved, len(records), total.Round(time.Millisecond))
+	return nil
+}
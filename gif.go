@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/render"
+)
+
+func runGIF(args []string) error {
+	flags := flag.NewFlagSet("gif", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: chess2 gif [options] <file.pgn>")
+		flags.PrintDefaults()
+	}
+	options := render.DefaultOptions()
+	options.CellSize = 48
+	gifOptions := render.DefaultGIFOptions()
+	output := flags.String("o", "game.gif", "animation file to write")
+	index := flags.Int("game", 1, "number of the game in the PGN file")
+	flags.DurationVar(&gifOptions.Delay, "delay", gifOptions.Delay, "time every move is shown")
+	flags.DurationVar(&gifOptions.FinalDelay, "final", gifOptions.FinalDelay, "time the final position is shown")
+	flags.BoolVar(&gifOptions.Caption, "caption", gifOptions.Caption, "show the move in SAN below the board")
+	flags.IntVar(&options.CellSize, "size", options.CellSize, "square size in pixels")
+	flags.BoolVar(&options.Flipped, "flip", false, "show the board from black's side")
+	flags.BoolVar(&options.Coordinates, "coords", options.Coordinates, "label the files and ranks")
+	flags.StringVar(&options.Theme, "theme", options.Theme, "theme from the themes directory")
+	flags.StringVar(&options.SpriteDir, "sprites", "", "directory with a custom sprite pack, replacing the theme's")
+	flags.Parse(args)
+
+	// the options may also follow the file
+	path := flags.Arg(0)
+	if flags.NArg() > 0 {
+		flags.Parse(flags.Args()[1:])
+	}
+
+	if path == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("expected exactly one PGN file")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	games, err := chess2.ReadPGN(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	if *index < 1 || *index > len(games) {
+		return fmt.Errorf("%s has %d games, there is no game %d", path, len(games), *index)
+	}
+
+	options.Assets = assets
+	return writeGIF(*output, games[*index - 1], options, gifOptions)
+}
+
+func writeGIF(path string, game *chess2.Game, options render.Options, gifOptions render.GIFOptions) error {
+	if options.CellSize < 1 {
+		return errors.New("square size must be positive")
+	}
+
+	renderer, err := render.New(options)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return renderer.WriteGIF(file, game, gifOptions)
+}
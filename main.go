@@ -1,35 +1,98 @@
 package main
 
 import (
-	rl "github.com/gen2brain/raylib-go/raylib"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	chess2 "github.com/girvel/chess2/src"
-	"github.com/girvel/chess2/src/iosystem"
+	"github.com/girvel/chess2/src/frontend"
+	"github.com/girvel/chess2/src/render"
+	"github.com/girvel/chess2/src/terminal"
 )
 
+var commands = map[string]func(args []string) error{
+	"bench": runBench,
+	"epd": runEPD,
+	"gif": runGIF,
+	"match": runMatch,
+	"render": runRender,
+}
+
 func main() {
-	iosystem.Init()
-	defer iosystem.Deinit()
-	board := chess2.EmptyBoard()
-	ai := chess2.CreateAi(*board)
-
-	for {
-		iosystem.Draw(board)
-
-		playerMove, shouldClose := iosystem.ReadInput(board)
-		if playerMove != nil {
-			board.Move(*playerMove)
-			ai.PushMove(*playerMove)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command, ok := commands[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+			os.Exit(2)
 		}
 
-		if shouldClose {
-			break
+		if err := command(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
+	}
+
+	if err := play(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// terminalFrame is how often the terminal front-end polls for input and the AI
+const terminalFrame = 50 * time.Millisecond
+
+func play(args []string) error {
+	flags := flag.NewFlagSet("chess2", flag.ExitOnError)
+	pgnPath := flags.String("pgn", "", "file to save the game to, with the board annotations as comments")
+	gifPath := flags.String("gif", "", "file to save an animation of the game to on exit")
+	tui := flags.Bool("tui", false, "play in the terminal instead of a window")
+	spriteDir := flags.String("sprites", "", "directory with a custom sprite pack, replacing the theme's")
+	theme := flags.String("theme", "default", "theme from the themes directory")
+	window := addWindowFlags(flags)
+	flags.Parse(args)
 
-		if board.Turn == chess2.SideBlack {
-			if m := ai.PopResponse(); m != nil {
-				rl.TraceLog(rl.LogInfo, "AI: %s", m)
-				board.Move(*m)
-			}
+	game := chess2.NewGame(*chess2.EmptyBoard())
+	game.Tags["Date"] = time.Now().Format("2006.01.02")
+	game.Tags["White"] = "human"
+	game.Tags["Black"] = "chess2"
+
+	var session *frontend.Session
+	if *tui {
+		session = frontend.NewSession(terminal.New(os.Stdin, os.Stdout), game, chess2.DefaultSearchOptions())
+		session.Frame = terminalFrame
+	} else {
+		windowFrontend, logf, err := window.newWindow(*theme, *spriteDir)
+		if err != nil {
+			return err
+		}
+		session = frontend.NewSession(windowFrontend, game, chess2.DefaultSearchOptions())
+		session.Logf = logf
+	}
+	session.Run()
+
+	if *gifPath != "" {
+		renderOptions := render.DefaultOptions()
+		renderOptions.CellSize = 48
+		renderOptions.Theme = *theme
+		renderOptions.SpriteDir = *spriteDir
+		renderOptions.Assets = assets
+		if err := writeGIF(*gifPath, game, renderOptions, render.DefaultGIFOptions()); err != nil {
+			return err
 		}
 	}
+
+	if *pgnPath == "" {
+		return nil
+	}
+
+	file, err := os.Create(*pgnPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return game.WritePGN(file)
 }
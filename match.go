@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+func runMatch(args []string) error {
+	flags := flag.NewFlagSet("match", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: chess2 match [options] <engine1> <engine2>")
+		fmt.Fprintln(flags.Output(), "engines are builtin[,nokillers,nohistory,nonullmove,nolmr,nopvs,noaspiration,threads=N]")
+		fmt.Fprintln(flags.Output(), "or uci:<command>")
+		flags.PrintDefaults()
+	}
+	games := flags.Int("games", 100, "number of games")
+	concurrency := flags.Int("concurrency", 1, "games played in parallel")
+	depth := flags.Int("depth", 0, "fixed search depth, overrides -time")
+	nodes := flags.Int64("nodes", 0, "fixed node count, overrides -time")
+	movetime := flags.Duration("time", 100 * time.Millisecond, "search time per move")
+	openingsPath := flags.String("openings", "", "file with one FEN or EPD opening per line")
+	maxPlies := flags.Int("maxplies", 300, "adjudicate longer games as draws")
+	pgnPath := flags.String("pgn", "match.pgn", "file to save the games to")
+	sprt := flags.String("sprt", "", "run an SPRT as elo0,elo1[,alpha,beta], stopping on a verdict")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("expected two engines")
+	}
+
+	var factories [2]func() (chess2.Engine, error)
+	for i := range factories {
+		factory, err := parseEngine(flags.Arg(i))
+		if err != nil {
+			return err
+		}
+		factories[i] = factory
+	}
+
+	options := chess2.MatchOptions{
+		Games: *games,
+		Concurrency: *concurrency,
+		Limits: chess2.SearchLimits{Time: *movetime},
+		MaxPlies: *maxPlies,
+	}
+	if *depth > 0 || *nodes > 0 {
+		options.Limits = chess2.SearchLimits{Depth: *depth, Nodes: *nodes}
+	}
+
+	if *openingsPath != "" {
+		file, err := os.Open(*openingsPath)
+		if err != nil {
+			return err
+		}
+		records, skipped, err := chess2.ReadEPD(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		for _, err := range skipped {
+			fmt.Fprintf(os.Stderr, "skipped opening %s\n", err)
+		}
+		for _, record := range records {
+			options.Openings = append(options.Openings, *record.Board)
+		}
+	}
+
+	if *sprt != "" {
+		parameters, err := parseSPRT(*sprt)
+		if err != nil {
+			return err
+		}
+		options.SPRT = parameters
+	}
+
+	pgn, err := os.Create(*pgnPath)
+	if err != nil {
+		return err
+	}
+	defer pgn.Close()
+
+	event := fmt.Sprintf("%s vs %s", flags.Arg(0), flags.Arg(1))
+	stats, err := chess2.PlayMatch(
+		func(i int) (chess2.Engine, error) { return factories[i]() },
+		options,
+		func(round int, game *chess2.Game, stats chess2.MatchStats) {
+			game.Tags["Event"] = event
+			game.Tags["Date"] = time.Now().Format("2006.01.02")
+			if err := game.WritePGN(pgn); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			fmt.Printf(
+				"Game %d: %s (%s)  W/D/L %d/%d/%d\n",
+				round + 1, game.Result, game.Termination, stats.Wins, stats.Draws, stats.Losses,
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	diff, margin := stats.Elo()
+	fmt.Printf("\n%s\n", event)
+	fmt.Printf("Games: %d, W/D/L: %d/%d/%d\n", stats.Games(), stats.Wins, stats.Draws, stats.Losses)
+	fmt.Printf("Elo difference: %.1f +/- %.1f\n", diff, margin)
+	if options.SPRT != nil {
+		lower, upper := options.SPRT.Bounds()
+		verdict := stats.Verdict(*options.SPRT)
+		switch verdict {
+		case "H1": verdict = "H1 accepted, the first engine is stronger"
+		case "H0": verdict = "H0 accepted, the first engine is not stronger"
+		default: verdict = "inconclusive"
+		}
+		fmt.Printf("SPRT: LLR %.2f (%.2f, %.2f), %s\n", stats.LLR(*options.SPRT), lower, upper, verdict)
+	}
+	return nil
+}
+
+func parseEngine(spec string) (func() (chess2.Engine, error), error) {
+	if command, ok := strings.CutPrefix(spec, "uci:"); ok {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("engine %q has no command", spec)
+		}
+		return func() (chess2.Engine, error) {
+			return chess2.StartUCIEngine(fields[0], fields[1:]...)
+		}, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	if parts[0] != "builtin" {
+		return nil, fmt.Errorf("unknown engine %q", spec)
+	}
+
+	options, err := parseSearchOptions(parts[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (chess2.Engine, error) {
+		return chess2.NewBuiltinEngine(spec, options), nil
+	}, nil
+}
+
+// parseSearchOptions reads single-threaded options without pondering, like
+// nonullmove or threads=4
+func parseSearchOptions(parts []string) (chess2.SearchOptions, error) {
+	options := chess2.DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = false
+	for _, part := range parts {
+		switch part {
+		case "": continue
+		case "nokillers": options.Killers = false
+		case "nohistory": options.History = false
+		case "nonullmove": options.NullMove = false
+		case "nolmr": options.LateMoveReductions = false
+		case "nopvs": options.PrincipalVariation = false
+		case "noaspiration": options.AspirationWindows = false
+		default:
+			value, ok := strings.CutPrefix(part, "threads=")
+			threads, err := strconv.Atoi(value)
+			if !ok || err != nil || threads < 1 {
+				return options, fmt.Errorf("unknown search option %q", part)
+			}
+			options.Threads = threads
+		}
+	}
+	return options, nil
+}
+
+func parseSPRT(s string) (*chess2.SPRT, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 4 {
+		return nil, fmt.Errorf("SPRT %q should be elo0,elo1[,alpha,beta]", s)
+	}
+
+	values := []float64{0, 0, 0.05, 0.05}
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SPRT %q: %w", s, err)
+		}
+		values[i] = value
+	}
+	return &chess2.SPRT{Elo0: values[0], Elo1: values[1], Alpha: values[2], Beta: values[3]}, nil
+}
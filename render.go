@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/render"
+)
+
+// arrowsFlag collects the repeated -arrow flags
+type arrowsFlag []render.Arrow
+
+func (a *arrowsFlag) String() string {
+	return fmt.Sprint(len(*a), " arrows")
+}
+
+func (a *arrowsFlag) Set(s string) error {
+	arrow, err := render.ParseArrow(s)
+	if err != nil {
+		return err
+	}
+	*a = append(*a, arrow)
+	return nil
+}
+
+func runRender(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: chess2 render [options] <fen>")
+		flags.PrintDefaults()
+	}
+	options := render.DefaultOptions()
+	output := flags.String("o", "board.png", "image file to write")
+	flags.IntVar(&options.CellSize, "size", options.CellSize, "square size in pixels")
+	flags.BoolVar(&options.Flipped, "flip", false, "show the board from black's side")
+	flags.BoolVar(&options.Coordinates, "coords", options.Coordinates, "label the files and ranks")
+	lastMove := flags.String("lastmove", "", "highlight the squares of a move like e2e4")
+	var arrows arrowsFlag
+	flags.Var(&arrows, "arrow", "draw an arrow like e2e4, optionally coloured like Re2e4 (G, R, B, Y); repeatable")
+	flags.StringVar(&options.Theme, "theme", options.Theme, "theme from the themes directory")
+	flags.StringVar(&options.SpriteDir, "sprites", "", "directory with a custom sprite pack, replacing the theme's")
+	flags.Parse(args)
+
+	// the options may also follow the FEN
+	fen := flags.Arg(0)
+	if flags.NArg() > 0 {
+		flags.Parse(flags.Args()[1:])
+	}
+
+	if fen == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("expected exactly one FEN")
+	}
+	if options.CellSize < 1 {
+		return errors.New("square size must be positive")
+	}
+
+	board, err := chess2.ParseFEN(fen)
+	if err != nil {
+		return err
+	}
+
+	// the board's last move is only known from the en passant square otherwise
+	board.LastMove = nil
+	if *lastMove != "" {
+		arrow, err := render.ParseArrow(*lastMove)
+		if err != nil {
+			return fmt.Errorf("invalid last move %q", *lastMove)
+		}
+		move := chess2.NewMove(arrow.X1, arrow.Y1, arrow.X2, arrow.Y2)
+		board.LastMove = &move
+	}
+
+	options.Arrows = arrows
+	options.Assets = assets
+	renderer, err := render.New(options)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return png.Encode(file, renderer.Render(board))
+}
@@ -2,67 +2,187 @@ package chess2
 
 import (
 	"context"
-	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Ai struct {
-	board *Board
-	nextMove Move
-	responseChannel chan map[Move]Move
-	responseCtx context.Context
-	responseCancel context.CancelFunc
+	board Board
+	options SearchOptions
+	tt *transpositionTable
+	pending *pendingSearch
 	lastMoveTime time.Time
+	mutex sync.Mutex
+	analysis Analysis
+	prediction *Move
+	ponderStats PonderStats
+	hints chan []SearchResult
+	limits SearchLimits
 }
 
-func CreateAi(board Board) *Ai {
-	if cost == nil {
-		var empty [BoardSize * BoardSize]float64
-		cost = append(cost, empty)
-
-		for _, e := range rawCost {
-			cost = append(cost, e)
-			var mirror [BoardSize * BoardSize]float64
-			for i, v := range e {
-				x := i % BoardSize
-				y := BoardSize - i / BoardSize - 1
-				mirror[x + y * BoardSize] = -v
-			}
-			cost = append(cost, mirror)
-		}
-	}
+const hintTime = 2 * time.Second
 
-	ai := Ai{
-		responseChannel: make(chan map[Move]Move),
-		board: &board,
-		lastMoveTime: time.Now(),
+// Analysis is the AI's latest search together with the position it searched
+type Analysis struct {
+	Root Board
+	SearchInfo
+}
+
+type PonderStats struct {
+	Hits, Misses int
+}
+
+type pendingSearch struct {
+	results chan SearchResult
+	cancel context.CancelFunc
+	control *searchControl
+	pondering atomic.Bool
+	ponderMove Move
+}
+
+func CreateAi(board Board, options SearchOptions) *Ai {
+	return &Ai{
+		board: board,
+		options: options,
+		tt: newTranspositionTable(),
+		lastMoveTime: options.now(),
 	}
-	ai.launchSearch()
-	return &ai
 }
 
 func (ai *Ai) PushMove(m Move) {
-	time.AfterFunc(min(time.Since(ai.lastMoveTime), time.Second * 10), func() {
-		ai.responseCancel()
-	})
-	ai.nextMove = m
+	ai.board.Move(m)
+	ai.prediction = nil
+
+	now := ai.options.now()
+	thinkTime := min(now.Sub(ai.lastMoveTime), time.Second * 10)
+	limits := ai.limits
+	if limits == (SearchLimits{}) {
+		limits.Time = thinkTime
+	}
+
+	p := ai.pending
+	hit := p != nil && p.pondering.Load() && p.ponderMove == m
+	switch {
+	case hit && ai.limits == (SearchLimits{}):
+		ai.ponderStats.Hits++
+		p.control.setDeadline(now.Add(thinkTime))
+		p.pondering.Store(false)
+		return
+	case hit:
+		// a running search only takes a new deadline, so fixed limits restart
+		// it; the pondered lines are still in the transposition table
+		ai.ponderStats.Hits++
+	case p != nil:
+		ai.ponderStats.Misses++
+	}
+
+	if p != nil {
+		p.cancel()
+	}
+	ai.pending = ai.launchSearch(ai.board, limits, false)
 }
 
 func (ai *Ai) PopResponse() *Move {
+	p := ai.pending
+	if p == nil || p.pondering.Load() {
+		return nil
+	}
+
 	select {
-	case responses := <-ai.responseChannel:
-		result := responses[ai.nextMove]
-		ai.board.Move(ai.nextMove)
-		ai.board.Move(result)
-		ai.launchSearch()
-		ai.lastMoveTime = time.Now()
-		return &result
+	case result := <-p.results:
+		p.cancel()
+		ai.pending = nil
+		if result.Depth == 0 {
+			// there is no legal move
+			return nil
+		}
+		ai.board.Move(result.Move)
+		ai.lastMoveTime = ai.options.now()
+		if ai.options.Ponder && len(result.PV) >= 2 {
+			ai.ponder(result.PV[1])
+		}
+		return &result.Move
 	default:
 		return nil
 	}
 }
 
+// SetLimits fixes how long the AI searches its moves, instead of taking as
+// long as the opponent did; zero limits restore that
+func (ai *Ai) SetLimits(limits SearchLimits) {
+	ai.limits = limits
+}
+
+func (ai *Ai) Info() SearchInfo {
+	return ai.Analysis().SearchInfo
+}
+
+func (ai *Ai) Analysis() Analysis {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	return ai.analysis
+}
+
+// RequestHint starts analysing b for the side to move, the count best moves
+// are returned by PopHint when done
+func (ai *Ai) RequestHint(b Board, count int) {
+	hints := make(chan []SearchResult, 1)
+	ai.hints = hints
+	go func() {
+		hints <- analyse(context.Background(), &b, ai.options, ai.tt, SearchLimits{Time: hintTime}, count)
+	}()
+}
+
+func (ai *Ai) PopHint() []SearchResult {
+	select {
+	case result := <-ai.hints:
+		ai.hints = nil
+		return result
+	default:
+		return nil
+	}
+}
+
+// Prediction is the opponent's move the AI is currently pondering on
+func (ai *Ai) Prediction() *Move {
+	return ai.prediction
+}
+
+func (ai *Ai) PonderStats() PonderStats {
+	return ai.ponderStats
+}
+
+// ponder searches the position after the opponent's expected move while they
+// think; PushMove either keeps the search on a hit or restarts it on a miss
+func (ai *Ai) ponder(m Move) {
+	ai.prediction = &m
+	ai.pending = ai.launchSearch(*ai.board.Apply(m), SearchLimits{}, true)
+	ai.pending.ponderMove = m
+}
+
+func (ai *Ai) launchSearch(board Board, limits SearchLimits, pondering bool) *pendingSearch {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &pendingSearch{
+		results: make(chan SearchResult, 1),
+		cancel: cancel,
+		control: newSearchControl(ai.options, limits),
+	}
+	p.pondering.Store(pondering)
+
+	go func() {
+		p.results <- search(ctx, &board, ai.options, ai.tt, p.control, func(info SearchInfo) {
+			if p.pondering.Load() {
+				return
+			}
+			ai.mutex.Lock()
+			defer ai.mutex.Unlock()
+			ai.analysis = Analysis{Root: board, SearchInfo: info}
+		})
+	}()
+	return p
+}
+
 var rawCost = [][BoardSize * BoardSize]float64{
 	{0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 
 	 2.0, 2.0, 2.0, 2.0, 2.0, 2.0, 2.0, 2.0, 
@@ -121,6 +241,22 @@ var rawCost = [][BoardSize * BoardSize]float64{
 
 var cost [][BoardSize * BoardSize]float64
 
+func init() {
+	var empty [BoardSize * BoardSize]float64
+	cost = append(cost, empty)
+
+	for _, e := range rawCost {
+		cost = append(cost, e)
+		var mirror [BoardSize * BoardSize]float64
+		for i, v := range e {
+			x := i % BoardSize
+			y := BoardSize - i / BoardSize - 1
+			mirror[x + y * BoardSize] = -v
+		}
+		cost = append(cost, mirror)
+	}
+}
+
 func evaluate(b *Board) float64 {
 	switch b.Winner {
 	case SideWhite: return 1000
@@ -134,140 +270,3 @@ func evaluate(b *Board) float64 {
 
 	return result
 }
-
-func getAllMoves(b *Board) []Move {
-	result := make([]Move, 0, 16)
-
-	for x := range BoardSize {
-		for y := range BoardSize {
-			piece := *b.At(x, y)
-			if piece.Side() != b.Turn {
-				continue
-			}
-
-			result = append(result, b.GetMoves(x, y)...)
-		}
-	}
-
-	score := func(m Move) float64 {
-		capture := *b.At(m.X2, m.Y2)
-		if capture == PieceNone {
-			return 0
-		}
-
-		attacker := *b.At(m.X1, m.Y1)
-		return 1000000 + 100 * Abs(cost[capture][m.X2 + BoardSize * m.Y2]) - Abs(cost[attacker][m.X1 + BoardSize * m.Y1])
-	}
-
-	slices.SortFunc(result, func(a, b Move) int { return Sign(score(b) - score(a)) })
-
-	return result
-}
-
-func alphaBeta(b *Board, depth int, alpha, beta float64, onlyCaptures bool) float64 {
-	if depth <= 0 || b.Winner != SideNone {
-		return evaluate(b)
-	}
-
-	isMaximizing := b.Turn == SideWhite
-	if isMaximizing {
-		maxEval := -1000000.
-		for _, m := range getAllMoves(b) {
-			if onlyCaptures && !m.IsCapture(b) { continue }
-			nextBoard := b.Apply(m)
-			var eval float64
-			if depth == 1 && m.IsCapture(b) {
-				eval = alphaBeta(nextBoard, 1, alpha, beta, true)
-			} else {
-				eval = alphaBeta(nextBoard, depth - 1, alpha, beta, onlyCaptures)
-			}
-			maxEval = max(maxEval, eval)
-			alpha = max(alpha, eval)
-			if beta <= alpha {
-				break
-			}
-		}
-		return maxEval
-	} else {
-		minEval := 1000000.
-		for _, m := range getAllMoves(b) {
-			if onlyCaptures && !m.IsCapture(b) { continue }
-			nextBoard := b.Apply(m)
-			var eval float64
-			if depth == 1 && m.IsCapture(b) {
-				eval = alphaBeta(nextBoard, 1, alpha, beta, true)
-			} else {
-				eval = alphaBeta(nextBoard, depth - 1, alpha, beta, onlyCaptures)
-			}
-			minEval = min(minEval, eval)
-			beta = min(beta, eval)
-			if beta <= alpha {
-				break
-			}
-		}
-		return minEval
-	}
-}
-
-func searchBestResponse(b *Board, out chan map[Move]Move, ctx context.Context) {
-	var currentResult, lastResult map[Move]Move
-	depth := 0
-	search: for {
-		depth += 1
-
-		type movePair struct {
-			move, response Move
-		}
-
-		results := make(chan movePair, 10)
-		var wg sync.WaitGroup
-		for _, m := range getAllMoves(b) {
-			wg.Go(func() {
-				nextBoard := b.Apply(m)
-				bestScore := 1000000.
-				var bestResponse Move
-				for _, response := range getAllMoves(nextBoard) {
-					score := alphaBeta(nextBoard.Apply(response), depth, -1000000., 1000000, false)
-					if score < bestScore {
-						bestResponse = response
-						bestScore = score
-					}
-				}
-				select {
-				case <-ctx.Done(): if depth > 1 { return }
-				case results <- movePair{ move: m, response: bestResponse }:
-				}
-			})
-		}
-
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
-
-		currentResult = make(map[Move]Move)
-		build: for {
-			select {
-			case <-ctx.Done():
-				if depth > 1 {
-					depth--
-					break search
-				}
-
-			case pair, ok := <-results:
-				if !ok {
-					break build
-				}
-				currentResult[pair.move] = pair.response
-			}
-		}
-		lastResult = currentResult
-	}
-	out <- lastResult
-	println("Depth", depth)
-}
-
-func (ai *Ai) launchSearch() {
-	ai.responseCtx, ai.responseCancel = context.WithCancel(context.Background())
-	go searchBestResponse(ai.board, ai.responseChannel, ai.responseCtx)
-}
@@ -0,0 +1,79 @@
+package chess2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAiAfterKingCapture(t *testing.T) {
+	board, err := ParseFEN("4k3/8/8/8/8/8/8/4RK2 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := board.ParseMove("e1e8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	ai := CreateAi(*board, options)
+	ai.PushMove(m)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for ai.pending != nil {
+		if reply := ai.PopResponse(); reply != nil {
+			t.Fatalf("AI replied %s after losing its king", reply)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("AI is still searching")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitResponse(t *testing.T, ai *Ai) *Move {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if reply := ai.PopResponse(); reply != nil {
+			return reply
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("AI is still searching")
+	return nil
+}
+
+func TestAiPonderHitKeepsLimits(t *testing.T) {
+	board, err := ParseFEN(StartFEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := board.ParseMove("e2e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = true
+	ai := CreateAi(*board, options)
+	ai.SetLimits(SearchLimits{Depth: 2})
+	ai.PushMove(m)
+	waitResponse(t, ai)
+
+	prediction := ai.Prediction()
+	if prediction == nil {
+		t.Fatal("AI doesn't ponder")
+	}
+	ai.PushMove(*prediction)
+	waitResponse(t, ai)
+
+	if stats := ai.PonderStats(); stats.Hits != 1 {
+		t.Errorf("got %d ponder hits, expected 1", stats.Hits)
+	}
+	if depth := ai.Info().Depth; depth != 2 {
+		t.Errorf("searched to depth %d after a ponder hit, expected 2", depth)
+	}
+}
@@ -0,0 +1,49 @@
+package chess2
+
+import (
+	"time"
+)
+
+// BenchPositions are searched by the bench command; the total node count of a
+// single-threaded bench is a signature that changes with search behaviour
+var BenchPositions = []string{
+	StartFEN,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 3 3",
+	"r1bq1rk1/ppp2ppp/2np1n2/2b1p3/2B1P3/2PP1N2/PP3PPP/RNBQ1RK1 w - - 0 7",
+	"2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"8/8/4k3/8/2p5/8/B2P2K1/8 w - - 0 1",
+}
+
+type BenchResult struct {
+	Nodes int64
+	Elapsed time.Duration
+}
+
+func (r BenchResult) NPS() int64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(r.Nodes) / r.Elapsed.Seconds())
+}
+
+func Bench(options SearchOptions, depth int, onPosition func(fen string, result SearchResult)) (BenchResult, error) {
+	var total BenchResult
+	for _, fen := range BenchPositions {
+		board, err := ParseFEN(fen)
+		if err != nil {
+			return total, err
+		}
+
+		start := time.Now()
+		result := Search(board, options, SearchLimits{Depth: depth})
+		total.Elapsed += time.Since(start)
+		total.Nodes += result.Nodes
+		if onPosition != nil {
+			onPosition(fen, result)
+		}
+	}
+	return total, nil
+}
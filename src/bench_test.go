@@ -0,0 +1,81 @@
+package chess2
+
+import (
+	"context"
+	"testing"
+)
+
+// benchBoard is the middlegame position the micro benchmarks measure
+func benchBoard(b *testing.B) *Board {
+	board, err := ParseFEN(BenchPositions[1])
+	if err != nil {
+		b.Fatal(err)
+	}
+	return board
+}
+
+func BenchmarkGetMoves(b *testing.B) {
+	board := benchBoard(b)
+	for b.Loop() {
+		for x := range BoardSize {
+			for y := range BoardSize {
+				board.GetMoves(x, y)
+			}
+		}
+	}
+}
+
+func BenchmarkIsMoveLegal(b *testing.B) {
+	board := benchBoard(b)
+	moves := board.AllMoves()
+	for b.Loop() {
+		for _, m := range moves {
+			board.IsMoveLegal(m)
+		}
+	}
+}
+
+func BenchmarkBoardMove(b *testing.B) {
+	board := benchBoard(b)
+	moves := board.AllMoves()
+	for b.Loop() {
+		for _, m := range moves {
+			next := *board
+			next.Move(m)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	board := benchBoard(b)
+	for b.Loop() {
+		evaluate(board)
+	}
+}
+
+func BenchmarkSEE(b *testing.B) {
+	board := benchBoard(b)
+	moves := board.AllMoves()
+	for b.Loop() {
+		for _, m := range moves {
+			if m.IsCapture(board) {
+				board.SEE(m)
+			}
+		}
+	}
+}
+
+func BenchmarkNegamax(b *testing.B) {
+	board := benchBoard(b)
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	for b.Loop() {
+		b.StopTimer()
+		s := newSearcher(
+			context.Background(), options, newTranspositionTable(),
+			newSearchControl(options, SearchLimits{}),
+		)
+		b.StartTimer()
+		s.negamax(board, 3, 0, -infinity, infinity, false)
+	}
+}
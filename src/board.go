@@ -2,6 +2,7 @@ package chess2
 
 import (
 	"fmt"
+	"strings"
 )
 
 const BoardSize int = 8
@@ -33,6 +34,11 @@ func (p Piece) Is(side Side) bool {
 	return p != PieceNone && int(p) % 2 == int(side)
 }
 
+// Letter is the English SAN letter of the piece, empty for pawns
+func (p Piece) Letter() string {
+	return [...]string{"", "", "", "N", "N", "B", "B", "R", "R", "Q", "Q", "K", "K"}[p]
+}
+
 func (p Piece) Side() Side {
 	switch p {
 		case PieceNone: return SideNone
@@ -46,12 +52,14 @@ type Board struct {
 	LastMove *Move
 	A1Moved, A8Moved, E1Moved, E8Moved, H1Moved, H8Moved bool
 	Winner Side
+	HalfmoveClock, FullmoveNumber int
 }
 
 func EmptyBoard() *Board {
 	var result Board
 	result.Turn = SideWhite
 	result.Winner = SideNone
+	result.FullmoveNumber = 1
 
 	*result.At(0, 0) = PieceBlackRook
 	*result.At(1, 0) = PieceBlackKnight
@@ -101,6 +109,9 @@ func (b *Board) At(x, y int) *Piece {
 
 type Move struct {
 	X1, Y1, X2, Y2 int
+	// Promotion is the piece a pawn turns into on the last rank; PieceNone
+	// stands for a queen
+	Promotion Piece
 }
 
 func NewMove(x1, y1, x2, y2 int) Move {
@@ -108,11 +119,15 @@ func NewMove(x1, y1, x2, y2 int) Move {
 }
 
 func (m Move) String() string {
-	return fmt.Sprintf("%c%d-%c%d", 'a' + m.X1, 8 - m.Y1, 'a' + m.X2, 8 - m.Y2)
+	result := fmt.Sprintf("%c%d-%c%d", 'a' + m.X1, 8 - m.Y1, 'a' + m.X2, 8 - m.Y2)
+	if m.Promotion != PieceNone {
+		result += strings.ToLower(m.Promotion.Letter())
+	}
+	return result
 }
 
 func (m Move) IsCapture(board *Board) bool {
-	return *board.At(m.X2, m.Y2) != PieceNone
+	return *board.At(m.X2, m.Y2) != PieceNone || board.WillBeEnPassant(m)
 }
 
 func (b *Board) Move(move Move) {
@@ -135,16 +150,28 @@ func (b *Board) Move(move Move) {
 	source := b.At(move.X1, move.Y1)
 	dest := b.At(move.X2, move.Y2)
 
+	if *dest != PieceNone || *source == PieceWhitePawn || *source == PieceBlackPawn {
+		b.HalfmoveClock = 0
+	} else {
+		b.HalfmoveClock++
+	}
+	if b.Turn == SideBlack {
+		b.FullmoveNumber++
+	}
+
 	switch *dest {
 	case PieceWhiteKing: b.Winner = SideBlack
 	case PieceBlackKing: b.Winner = SideWhite
 	}
 
-	if move.Y2 == 0 && *source == PieceWhitePawn {
+	switch {
+	case move.Promotion != PieceNone && b.IsPromotion(move):
+		*dest = move.Promotion
+	case move.Y2 == 0 && *source == PieceWhitePawn:
 		*dest = PieceWhiteQueen
-	} else if move.Y2 == 7 && *source == PieceBlackPawn {
+	case move.Y2 == 7 && *source == PieceBlackPawn:
 		*dest = PieceBlackQueen
-	} else {
+	default:
 		*dest = *source
 	}
 	*source = PieceNone
@@ -159,6 +186,14 @@ func (b *Board) Move(move Move) {
 	case move.X1 == 4 && move.Y1 == 7: b.E1Moved = true
 	case move.X1 == 7 && move.Y1 == 7: b.H1Moved = true
 	}
+
+	// a rook captured on its home square can't castle either
+	switch {
+	case move.X2 == 0 && move.Y2 == 0: b.A8Moved = true
+	case move.X2 == 7 && move.Y2 == 0: b.H8Moved = true
+	case move.X2 == 0 && move.Y2 == 7: b.A1Moved = true
+	case move.X2 == 7 && move.Y2 == 7: b.H1Moved = true
+	}
 }
 
 func (b *Board) Apply(move Move) *Board {
@@ -176,7 +211,8 @@ func (b *Board) WillBeEnPassant(m Move) bool {
 
 	direction := int(1 - 2 * b.Turn)
 	centerline := int(4 - b.Turn)
-	if m.Y1 != centerline ||
+	if b.LastMove == nil ||
+		m.Y1 != centerline ||
 		m.Y2 != centerline + direction ||
 		Abs(m.X2 - m.X1) != 1 ||
 		b.LastMove.Y1 != centerline + 2 * direction ||
@@ -194,12 +230,21 @@ func (b *Board) WillBeEnPassant(m Move) bool {
 	}
 }
 
+// CanBeAttacked reports whether the opponent of the side to move could
+// capture on (x, y)
 func (b *Board) CanBeAttacked(x, y int) bool {
-	oppositeSide := 1 - b.Turn
+	return b.IsAttackedBy(1 - b.Turn, x, y)
+}
+
+// IsAttackedBy reports whether any piece of side could capture on (x, y),
+// whoever's turn it is
+func (b *Board) IsAttackedBy(side Side, x, y int) bool {
+	attacker := *b
+	attacker.Turn = side
 	for ix := range BoardSize {
 		for iy := range BoardSize {
 			piece := *b.At(ix, iy)
-			if piece.Is(oppositeSide) && b.IsMoveLegal(NewMove(ix, iy, x, y)) {
+			if piece.Is(side) && attacker.IsMoveLegal(NewMove(ix, iy, x, y)) {
 				return true
 			}
 		}
@@ -208,32 +253,58 @@ func (b *Board) CanBeAttacked(x, y int) bool {
 	return false
 }
 
+func (b *Board) InCheck() bool {
+	king := PieceWhiteKing
+	if b.Turn == SideBlack {
+		king = PieceBlackKing
+	}
+
+	for i, piece := range b.inner {
+		if piece == king {
+			return b.CanBeAttacked(i % BoardSize, i / BoardSize)
+		}
+	}
+
+	return false
+}
+
+// WillBeCastle reports whether m castles; the king may not start on or pass
+// through a square the opponent attacks
 func (b *Board) WillBeCastle(m Move) bool {
-	// NEXT check attack on passed square
 	var backline int
-	thisSide := b.Turn
-	oppositeSide := 1 - b.Turn
+	var king, rook Piece
+	var kingMoved, rookMoved bool
 	direction := Sign(m.X2 - m.X1)
+	rookX := 7
+	if direction < 0 {
+		rookX = 0
+	}
+
 	if b.Turn == SideWhite {
-		if b.H1Moved || b.E1Moved {
-			return false
-		}
 		backline = 7
+		king, rook = PieceWhiteKing, PieceWhiteRook
+		kingMoved = b.E1Moved
+		rookMoved = rookX == 0 && b.A1Moved || rookX == 7 && b.H1Moved
 	} else {
-		if b.H8Moved || b.E8Moved {
-			return false
-		}
 		backline = 0
+		king, rook = PieceBlackKing, PieceBlackRook
+		kingMoved = b.E8Moved
+		rookMoved = rookX == 0 && b.A8Moved || rookX == 7 && b.H8Moved
 	}
 
-	if (m != NewMove(4, backline, 4 + 2 * direction, backline) ||
-		*b.At(4 + direction, backline) != PieceNone ||
-		*b.At(4 + 2 * direction, backline) != PieceNone) {
+	if kingMoved || rookMoved || direction == 0 ||
+		m != NewMove(4, backline, 4 + 2 * direction, backline) ||
+		*b.At(4, backline) != king ||
+		*b.At(rookX, backline) != rook {
 		return false
 	}
 
-	b.Turn = oppositeSide
-	defer func() {b.Turn = thisSide}()
+	for x := 4 + direction; x != rookX; x += direction {
+		if *b.At(x, backline) != PieceNone {
+			return false
+		}
+	}
+
 	return !b.CanBeAttacked(4, backline) && !b.CanBeAttacked(4 + direction, backline)
 }
 
@@ -248,6 +319,14 @@ func (b *Board) IsMoveLegal(m Move) bool {
 		return false
 	}
 
+	if m.Promotion != PieceNone && (
+		!b.IsPromotion(m) ||
+		!m.Promotion.Is(b.Turn) ||
+		m.Promotion < PieceWhiteKnight ||
+		m.Promotion > PieceBlackQueen) {
+		return false
+	}
+
 	if b.WillBeEnPassant(m) ||
 		b.WillBeCastle(m) {
 		return true
@@ -389,7 +468,16 @@ func (b *Board) GetMoves(x, y int) []Move {
 
 	var result []Move = make([]Move, 0, len(potential))
 	for _, m := range potential {
-		if b.IsMoveLegal(m) {
+		if !b.IsMoveLegal(m) {
+			continue
+		}
+
+		if b.IsPromotion(m) {
+			for _, promotion := range []Piece{PieceWhiteQueen, PieceWhiteKnight, PieceWhiteRook, PieceWhiteBishop} {
+				m.Promotion = promotion + Piece(1 - source.Side())
+				result = append(result, m)
+			}
+		} else {
 			result = append(result, m)
 		}
 	}
@@ -397,3 +485,69 @@ func (b *Board) GetMoves(x, y int) []Move {
 	return result
 }
 
+func (b *Board) AllMoves() []Move {
+	var result []Move
+	for x := range BoardSize {
+		for y := range BoardSize {
+			if b.At(x, y).Is(b.Turn) {
+				result = append(result, b.GetMoves(x, y)...)
+			}
+		}
+	}
+	return result
+}
+
+func (b *Board) IsPromotion(m Move) bool {
+	source := *b.At(m.X1, m.Y1)
+	return source == PieceWhitePawn && m.Y2 == 0 || source == PieceBlackPawn && m.Y2 == BoardSize - 1
+}
+
+
+var seeValues = [...]int{0, 100, 100, 300, 300, 300, 300, 500, 500, 900, 900, 100000, 100000}
+
+// SEE statically evaluates the exchange started by m on its target square, in
+// centipawns from the perspective of the moving side
+func (b *Board) SEE(m Move) int {
+	var values []int
+	if b.WillBeEnPassant(m) {
+		values = append(values, seeValues[PieceWhitePawn])
+	} else {
+		values = append(values, seeValues[*b.At(m.X2, m.Y2)])
+	}
+
+	board := b.Apply(m)
+	for {
+		recapture, ok := board.leastValuableAttacker(m.X2, m.Y2)
+		if !ok {
+			break
+		}
+		values = append(values, seeValues[*board.At(m.X2, m.Y2)])
+		board.Move(recapture)
+	}
+
+	result := values[len(values) - 1]
+	for i := len(values) - 2; i >= 0; i-- {
+		result = values[i] - max(0, result)
+	}
+	return result
+}
+
+func (b *Board) leastValuableAttacker(x, y int) (Move, bool) {
+	var result Move
+	found := false
+	for ix := range BoardSize {
+		for iy := range BoardSize {
+			piece := *b.At(ix, iy)
+			if !piece.Is(b.Turn) || found && seeValues[piece] >= seeValues[*b.At(result.X1, result.Y1)] {
+				continue
+			}
+
+			m := NewMove(ix, iy, x, y)
+			if b.IsMoveLegal(m) {
+				result = m
+				found = true
+			}
+		}
+	}
+	return result, found
+}
@@ -0,0 +1,93 @@
+package chess2
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen string
+		move string
+		see int
+	}{
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", 100},
+		{"4k3/8/3p4/4p3/8/5N2/8/4K3 w - - 0 1", "f3e5", -200},
+		{"4k3/2p5/3q4/8/8/8/8/3RK3 w - - 0 1", "d1d6", 400},
+		{"4k3/8/8/3p4/8/8/8/3QK3 w - - 0 1", "d1d5", 100},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", 0},
+	}
+
+	for _, test := range tests {
+		board, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := board.ParseMove(test.move)
+		if err != nil {
+			t.Errorf("%s: %s", test.fen, err)
+			continue
+		}
+		if see := board.SEE(m); see != test.see {
+			t.Errorf("%s: SEE of %s is %d, expected %d", test.fen, test.move, see, test.see)
+		}
+	}
+}
+
+func TestCastlingRights(t *testing.T) {
+	tests := []struct {
+		fen string
+		move string
+		after string
+	}{
+		{"r3k3/8/8/8/8/8/8/R3K3 w Qq - 0 1", "a1a8", "R3k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+		{"4k2r/8/8/8/8/8/8/4K2R b Kk - 0 1", "h8h1", "4k3/8/8/8/8/8/8/4K2r w - - 0 2"},
+		{"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "h1h2", "4k3/8/8/8/8/8/7R/R3K3 b Q - 1 1"},
+		{"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", "4k3/8/8/8/8/8/8/2KR3R b - - 1 1"},
+	}
+
+	for _, test := range tests {
+		board, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := board.ParseMove(test.move)
+		if err != nil {
+			t.Errorf("%s: %s", test.fen, err)
+			continue
+		}
+		if after := board.Apply(m).FEN(); after != test.after {
+			t.Errorf("%s: %s gives %s, expected %s", test.fen, test.move, after, test.after)
+		}
+	}
+
+	board, err := ParseFEN("4k3/8/8/8/8/8/8/RN2K3 w Q - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.WillBeCastle(NewMove(4, 7, 2, 7)) {
+		t.Error("castled queenside through a knight on b1")
+	}
+}
+
+func TestCanBeAttacked(t *testing.T) {
+	// the black rook on f8 attacks f1, the white king's path when castling
+	board, err := ParseFEN("4kr2/8/8/8/8/8/8/4K2R w K - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !board.CanBeAttacked(5, 7) || board.CanBeAttacked(4, 7) {
+		t.Error("expected only f1 to be attacked by black")
+	}
+	if !board.IsAttackedBy(SideWhite, 7, 0) || board.IsAttackedBy(SideBlack, 6, 7) {
+		t.Error("expected the white rook to attack h8 and black not to attack g1")
+	}
+	if board.WillBeCastle(NewMove(4, 7, 6, 7)) {
+		t.Error("castled through the attacked f1")
+	}
+
+	*board.At(5, 0) = PieceNone
+	if !board.WillBeCastle(NewMove(4, 7, 6, 7)) {
+		t.Error("could not castle without the rook on f8")
+	}
+}
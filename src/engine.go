@@ -0,0 +1,49 @@
+package chess2
+
+import (
+	"context"
+)
+
+// Engine is anything that can choose moves in a game, used to play matches
+type Engine interface {
+	Name() string
+	NewGame() error
+	BestMove(g *Game, limits SearchLimits) (Move, error)
+	Close() error
+}
+
+type BuiltinEngine struct {
+	name string
+	options SearchOptions
+	tt *transpositionTable
+}
+
+func NewBuiltinEngine(name string, options SearchOptions) *BuiltinEngine {
+	return &BuiltinEngine{
+		name: name,
+		options: options,
+		tt: newTranspositionTable(),
+	}
+}
+
+func (e *BuiltinEngine) Name() string {
+	return e.name
+}
+
+func (e *BuiltinEngine) NewGame() error {
+	e.tt = newTranspositionTable()
+	return nil
+}
+
+func (e *BuiltinEngine) BestMove(g *Game, limits SearchLimits) (Move, error) {
+	control := newSearchControl(e.options, limits)
+	result := search(context.Background(), g.Board(), e.options, e.tt, control, nil)
+	if result.Depth == 0 || !g.Board().IsMoveLegal(result.Move) {
+		return Move{}, ErrNoMove
+	}
+	return result.Move, nil
+}
+
+func (e *BuiltinEngine) Close() error {
+	return nil
+}
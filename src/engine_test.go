@@ -0,0 +1,47 @@
+package chess2
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBuiltinEngineWithoutMoves(t *testing.T) {
+	board, err := ParseFEN("8/8/8/8/8/8/8/4RK2 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = false
+	engine := NewBuiltinEngine("builtin", options)
+	if m, err := engine.BestMove(NewGame(*board), SearchLimits{Depth: 2}); !errors.Is(err, ErrNoMove) {
+		t.Errorf("got %s and %v, expected ErrNoMove", m, err)
+	}
+}
+
+// illegalEngine always answers a move the board doesn't allow
+type illegalEngine struct{}
+
+func (illegalEngine) Name() string { return "illegal" }
+func (illegalEngine) NewGame() error { return nil }
+func (illegalEngine) Close() error { return nil }
+
+func (illegalEngine) BestMove(g *Game, limits SearchLimits) (Move, error) {
+	return Move{}, fmt.Errorf("%w a8a8", ErrIllegalMove)
+}
+
+func TestPlayGameIllegalMove(t *testing.T) {
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	engine := NewBuiltinEngine("builtin", options)
+
+	game, err := PlayGame(illegalEngine{}, engine, *EmptyBoard(), SearchLimits{Depth: 1}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Result != ResultBlackWins || game.Termination != "illegal move a8a8" {
+		t.Errorf("got %s by %q, expected black to win by the illegal move", game.Result, game.Termination)
+	}
+}
@@ -0,0 +1,155 @@
+package chess2
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type EPDRecord struct {
+	ID string
+	Board *Board
+	BestMoves []Move
+	AvoidMoves []Move
+}
+
+func ParseEPD(line string) (*EPDRecord, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("EPD %q has less than 4 fields", line)
+	}
+
+	board, err := ParseFEN(strings.Join(fields[:4], " ") + " 0 1")
+	if err != nil {
+		return nil, err
+	}
+
+	result := EPDRecord{Board: board}
+	rest := line
+	for range 4 {
+		rest = strings.TrimSpace(rest)
+		if i := strings.IndexFunc(rest, isSpace); i >= 0 {
+			rest = rest[i:]
+		} else {
+			rest = ""
+		}
+	}
+
+	operations, err := parseEPDOperations(rest)
+	if err != nil {
+		return nil, fmt.Errorf("EPD %q: %w", line, err)
+	}
+
+	for _, operation := range operations {
+		switch operation[0] {
+		case "id":
+			if len(operation) > 1 {
+				result.ID = operation[1]
+			}
+
+		case "bm", "am":
+			for _, san := range operation[1:] {
+				m, err := board.ParseSAN(san)
+				if err != nil {
+					return nil, fmt.Errorf("EPD %q: %w", line, err)
+				}
+
+				if operation[0] == "bm" {
+					result.BestMoves = append(result.BestMoves, m)
+				} else {
+					result.AvoidMoves = append(result.AvoidMoves, m)
+				}
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// parseEPDOperations splits "bm Qd1+; id "WAC 001";" into opcodes followed
+// by their operands
+func parseEPDOperations(s string) ([][]string, error) {
+	var result [][]string
+	var current []string
+	var token strings.Builder
+	inToken, inQuotes := false, false
+
+	flush := func() {
+		if inToken {
+			current = append(current, token.String())
+			token.Reset()
+			inToken = false
+		}
+	}
+
+	for _, c := range s {
+		switch {
+		case inQuotes && c == '"':
+			inQuotes = false
+		case inQuotes:
+			token.WriteRune(c)
+		case c == '"':
+			inQuotes = true
+			inToken = true
+		case c == ';':
+			flush()
+			if len(current) > 0 {
+				result = append(result, current)
+			}
+			current = nil
+		case isSpace(c):
+			flush()
+		default:
+			token.WriteRune(c)
+			inToken = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	flush()
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result, nil
+}
+
+// ReadEPD reads every record of an EPD file; a record that fails to parse is
+// skipped and its error returned in skipped, so one bad line doesn't lose the
+// whole suite
+func ReadEPD(r io.Reader) (records []*EPDRecord, skipped []error, err error) {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		record, err := ParseEPD(text)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		if record.ID == "" {
+			record.ID = fmt.Sprintf("#%d", line)
+		}
+		records = append(records, record)
+	}
+	return records, skipped, scanner.Err()
+}
+
+// IsSolvedBy reports whether m is one of the best moves and none of the moves
+// to avoid
+func (r *EPDRecord) IsSolvedBy(m Move) bool {
+	if slices.Contains(r.AvoidMoves, m) {
+		return false
+	}
+	return len(r.BestMoves) == 0 || slices.Contains(r.BestMoves, m)
+}
@@ -0,0 +1,24 @@
+package chess2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadEPDSkipsBadRecords(t *testing.T) {
+	records, skipped, err := ReadEPD(strings.NewReader(
+		"4k3/8/8/8/1b6/2N5/8/4K1N1 w - - bm Ne2; id \"pinned\";\n" +
+		"4k3/8/8/8/8/8/8/4K3 w - - bm Qd1; id \"no queen\";\n" +
+		"6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - bm Ra8#; id \"mate\";\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[0].ID != "pinned" || records[1].ID != "mate" {
+		t.Errorf("read %d records, expected pinned and mate", len(records))
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0].Error(), "line 2:") {
+		t.Errorf("skipped %v, expected line 2", skipped)
+	}
+}
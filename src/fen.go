@@ -0,0 +1,162 @@
+package chess2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+var fenPieces = map[rune]Piece{
+	'P': PieceWhitePawn, 'p': PieceBlackPawn,
+	'N': PieceWhiteKnight, 'n': PieceBlackKnight,
+	'B': PieceWhiteBishop, 'b': PieceBlackBishop,
+	'R': PieceWhiteRook, 'r': PieceBlackRook,
+	'Q': PieceWhiteQueen, 'q': PieceBlackQueen,
+	'K': PieceWhiteKing, 'k': PieceBlackKing,
+}
+
+func ParseFEN(fen string) (*Board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("FEN %q has %d fields, expected at least 4", fen, len(fields))
+	}
+
+	var result Board
+	result.Winner = SideNone
+	result.FullmoveNumber = 1
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != BoardSize {
+		return nil, fmt.Errorf("FEN %q has %d ranks", fen, len(ranks))
+	}
+	for y, rank := range ranks {
+		x := 0
+		for _, c := range rank {
+			if c >= '1' && c <= '8' {
+				x += int(c - '0')
+				continue
+			}
+
+			piece, ok := fenPieces[c]
+			if !ok || x >= BoardSize {
+				return nil, fmt.Errorf("FEN %q has invalid rank %q", fen, rank)
+			}
+			*result.At(x, y) = piece
+			x++
+		}
+		if x != BoardSize {
+			return nil, fmt.Errorf("FEN %q has invalid rank %q", fen, rank)
+		}
+	}
+
+	switch fields[1] {
+	case "w": result.Turn = SideWhite
+	case "b": result.Turn = SideBlack
+	default: return nil, fmt.Errorf("FEN %q has invalid side to move %q", fen, fields[1])
+	}
+
+	castling := fields[2]
+	result.H1Moved = !strings.ContainsRune(castling, 'K')
+	result.A1Moved = !strings.ContainsRune(castling, 'Q')
+	result.H8Moved = !strings.ContainsRune(castling, 'k')
+	result.A8Moved = !strings.ContainsRune(castling, 'q')
+	result.E1Moved = result.H1Moved && result.A1Moved
+	result.E8Moved = result.H8Moved && result.A8Moved
+
+	if fields[3] != "-" {
+		x, y, ok := parseSquare(fields[3])
+		if !ok || y != 2 && y != 5 {
+			return nil, fmt.Errorf("FEN %q has invalid en passant square %q", fen, fields[3])
+		}
+		// en passant is derived from the last move, so restore the double push
+		move := NewMove(x, 6, x, 4)
+		if y == 2 {
+			move = NewMove(x, 1, x, 3)
+		}
+		result.LastMove = &move
+	}
+
+	if len(fields) >= 6 {
+		var err error
+		if result.HalfmoveClock, err = strconv.Atoi(fields[4]); err != nil {
+			return nil, fmt.Errorf("FEN %q has invalid halfmove clock: %w", fen, err)
+		}
+		if result.FullmoveNumber, err = strconv.Atoi(fields[5]); err != nil {
+			return nil, fmt.Errorf("FEN %q has invalid fullmove number: %w", fen, err)
+		}
+	}
+
+	return &result, nil
+}
+
+func (b *Board) FEN() string {
+	var result strings.Builder
+	for y := range BoardSize {
+		if y > 0 {
+			result.WriteByte('/')
+		}
+
+		empty := 0
+		for x := range BoardSize {
+			piece := *b.At(x, y)
+			if piece == PieceNone {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				result.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			for c, p := range fenPieces {
+				if p == piece {
+					result.WriteRune(c)
+					break
+				}
+			}
+		}
+		if empty > 0 {
+			result.WriteString(strconv.Itoa(empty))
+		}
+	}
+
+	if b.Turn == SideWhite {
+		result.WriteString(" w ")
+	} else {
+		result.WriteString(" b ")
+	}
+
+	castling := ""
+	if !b.E1Moved && !b.H1Moved { castling += "K" }
+	if !b.E1Moved && !b.A1Moved { castling += "Q" }
+	if !b.E8Moved && !b.H8Moved { castling += "k" }
+	if !b.E8Moved && !b.A8Moved { castling += "q" }
+	if castling == "" {
+		castling = "-"
+	}
+	result.WriteString(castling)
+
+	enPassant := "-"
+	if m := b.LastMove; m != nil && Abs(m.Y2 - m.Y1) == 2 {
+		piece := *b.At(m.X2, m.Y2)
+		if piece == PieceWhitePawn || piece == PieceBlackPawn {
+			enPassant = squareName(m.X2, (m.Y1 + m.Y2) / 2)
+		}
+	}
+
+	fmt.Fprintf(&result, " %s %d %d", enPassant, b.HalfmoveClock, b.FullmoveNumber)
+	return result.String()
+}
+
+func parseSquare(s string) (int, int, bool) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, 0, false
+	}
+	return int(s[0] - 'a'), BoardSize - int(s[1] - '0'), true
+}
+
+func squareName(x, y int) string {
+	return fmt.Sprintf("%c%d", 'a' + x, BoardSize - y)
+}
@@ -0,0 +1,41 @@
+package chess2
+
+import "testing"
+
+func TestFENRoundTrip(t *testing.T) {
+	for _, fen := range []string{
+		StartFEN,
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+		"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+		"4k3/8/8/8/8/8/8/R3K2R w Q - 0 1",
+		"6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 12 40",
+	} {
+		board, err := ParseFEN(fen)
+		if err != nil {
+			t.Errorf("%s: %s", fen, err)
+			continue
+		}
+		if result := board.FEN(); result != fen {
+			t.Errorf("%s was written back as %s", fen, result)
+		}
+	}
+}
+
+func TestStartFEN(t *testing.T) {
+	if fen := EmptyBoard().FEN(); fen != StartFEN {
+		t.Errorf("the starting board is %s, expected %s", fen, StartFEN)
+	}
+}
+
+func TestParseFENErrors(t *testing.T) {
+	for _, fen := range []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+	} {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("%q was accepted", fen)
+		}
+	}
+}
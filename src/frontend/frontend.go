@@ -0,0 +1,126 @@
+// Package frontend connects the game loop to the ways of showing the game and
+// reading the human's moves: a window, a terminal or a script
+package frontend
+
+import (
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// HumanSide is the side played through the front-end, the AI plays the other
+const HumanSide = chess2.SideWhite
+
+const hintCount = 3
+
+// State is everything a front-end shows; it is owned by the Session and only
+// read by front-ends
+type State struct {
+	Game *chess2.Game
+	// Analysis is the AI's latest search, a positive Mate means the AI has
+	// found a forced win
+	Analysis chess2.Analysis
+	// Hints are the best moves for the human after a hint request, cleared by
+	// the human's next move
+	Hints []chess2.SearchResult
+	HintsUsed int
+}
+
+type Input struct {
+	Move *chess2.Move
+	ShouldClose bool
+	// Hint asks the AI for the best moves, which arrive in State.Hints
+	Hint bool
+	// Animating is set while a move is still shown, the AI's reply should wait
+	Animating bool
+}
+
+type Frontend interface {
+	Init()
+	Draw(state *State)
+	// ReadInput must not block, it is called once per frame
+	ReadInput(state *State) Input
+	Deinit()
+}
+
+// Session plays a game between the human at a front-end and the AI
+type Session struct {
+	Frontend Frontend
+	Ai *chess2.Ai
+	State State
+	// Frame is the pause between frames for front-ends that don't wait for
+	// the screen themselves
+	Frame time.Duration
+	// Logf reports the AI's moves and ponder statistics when set
+	Logf func(format string, args ...any)
+}
+
+func NewSession(frontend Frontend, game *chess2.Game, options chess2.SearchOptions) *Session {
+	return &Session{
+		Frontend: frontend,
+		Ai: chess2.CreateAi(*game.Board(), options),
+		State: State{Game: game},
+	}
+}
+
+func (s *Session) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// Run plays until the front-end asks to close
+func (s *Session) Run() {
+	s.Frontend.Init()
+	defer s.Frontend.Deinit()
+
+	game := s.State.Game
+	for {
+		s.State.Analysis = s.Ai.Analysis()
+		s.Frontend.Draw(&s.State)
+
+		input := s.Frontend.ReadInput(&s.State)
+		if input.Hint {
+			s.Ai.RequestHint(*game.Board(), hintCount)
+		}
+		if hint := s.Ai.PopHint(); hint != nil && game.Board().Turn == HumanSide {
+			s.State.Hints = hint
+			s.State.HintsUsed++
+		}
+
+		if playerMove := input.Move; playerMove != nil {
+			prediction := s.Ai.Prediction()
+			game.Play(*playerMove)
+			s.Ai.PushMove(*playerMove)
+			s.State.Hints = nil
+			if prediction != nil {
+				stats := s.Ai.PonderStats()
+				s.logf(
+					"AI predicted %s, got %s (%d hits, %d misses)",
+					prediction, playerMove, stats.Hits, stats.Misses,
+				)
+			}
+		}
+
+		if input.ShouldClose {
+			break
+		}
+
+		over := game.Result != chess2.ResultNone || game.Board().Winner != chess2.SideNone
+		if game.Board().Turn != HumanSide && !input.Animating && !over {
+			if m := s.Ai.PopResponse(); m != nil {
+				info := s.Ai.Info()
+				if info.Mate != 0 {
+					s.logf("AI: %s (depth %d, mate in %d, %d nodes)", m, info.Depth, info.Mate, info.Nodes)
+				} else {
+					s.logf("AI: %s (depth %d, score %.2f, %d nodes)", m, info.Depth, info.Score, info.Nodes)
+				}
+				game.Play(*m)
+			}
+		}
+
+		if s.Frame > 0 {
+			time.Sleep(s.Frame)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package frontend
+
+import (
+	"fmt"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// Scripted is a front-end without a screen that plays a fixed list of moves,
+// for running whole games against the AI in tests
+type Scripted struct {
+	// Moves are played in order in coordinate notation or SAN
+	Moves []string
+	// Frames counts the calls to Draw
+	Frames int
+	// Err is set when a scripted move could not be played
+	Err error
+	next int
+}
+
+func NewScripted(moves ...string) *Scripted {
+	return &Scripted{Moves: moves}
+}
+
+func (s *Scripted) Init() {}
+
+func (s *Scripted) Deinit() {}
+
+func (s *Scripted) Draw(state *State) {
+	s.Frames++
+}
+
+// ReadInput closes once the game is over, the script has run out or a move
+// in it is illegal
+func (s *Scripted) ReadInput(state *State) Input {
+	board := state.Game.Board()
+	switch {
+	case state.Game.Result != chess2.ResultNone || s.Err != nil:
+		return Input{ShouldClose: true}
+	case board.Turn != HumanSide:
+		return Input{}
+	case s.next >= len(s.Moves):
+		return Input{ShouldClose: true}
+	}
+
+	move, err := board.ParseAnyMove(s.Moves[s.next])
+	if err != nil {
+		s.Err = fmt.Errorf("scripted move %d: %w", s.next + 1, err)
+		return Input{ShouldClose: true}
+	}
+	s.next++
+	return Input{Move: &move}
+}
@@ -0,0 +1,88 @@
+package frontend
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// playSession runs the script from fen against an AI searching to a fixed
+// depth on a single thread, so that its replies don't depend on timing
+func playSession(t *testing.T, fen string, moves ...string) (*chess2.Game, string) {
+	t.Helper()
+	board, err := chess2.ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := chess2.DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = false
+	game := chess2.NewGame(*board)
+	script := NewScripted(moves...)
+	session := NewSession(script, game, options)
+	session.Ai.SetLimits(chess2.SearchLimits{Depth: 3})
+	session.Frame = time.Millisecond
+	session.Run()
+	if script.Err != nil {
+		t.Fatal(script.Err)
+	}
+
+	var pgn strings.Builder
+	if err := game.WritePGN(&pgn); err != nil {
+		t.Fatal(err)
+	}
+	return game, pgn.String()
+}
+
+func TestSession(t *testing.T) {
+	tests := []struct {
+		name string
+		fen string
+		script []string
+		moves []string
+		result chess2.GameResult
+		movetext string
+	}{
+		{
+			"opening", chess2.StartFEN,
+			[]string{"e4", "Nf3"},
+			[]string{"e2e4", "d7d5", "g1f3", "d5e4"},
+			chess2.ResultNone, "1. e4 d5 2. Nf3 dxe4 *",
+		},
+		{
+			"checkmate", "6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1",
+			[]string{"Ra8#"},
+			[]string{"a1a8"},
+			chess2.ResultWhiteWins, "1. Ra8# 1-0",
+		},
+		{
+			"king capture", "4k3/8/8/8/8/8/8/4RK2 w - - 0 1",
+			[]string{"e1e8"},
+			[]string{"e1e8"},
+			chess2.ResultWhiteWins, "1. Rxe8# 1-0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game, pgn := playSession(t, test.fen, test.script...)
+
+			var moves []string
+			for _, m := range game.Moves {
+				moves = append(moves, m.UCI())
+			}
+			if strings.Join(moves, " ") != strings.Join(test.moves, " ") {
+				t.Errorf("got moves %v, expected %v", moves, test.moves)
+			}
+			if game.Result != test.result {
+				t.Errorf("got result %s, expected %s", game.Result, test.result)
+			}
+			if !strings.Contains(pgn, test.movetext) {
+				t.Errorf("PGN has no %q:\n%s", test.movetext, pgn)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package chess2
+
+import (
+	"fmt"
+)
+
+type GameResult string
+
+const (
+	ResultNone GameResult = "*"
+	ResultWhiteWins GameResult = "1-0"
+	ResultBlackWins GameResult = "0-1"
+	ResultDraw GameResult = "1/2-1/2"
+)
+
+// Game is a board together with the history that led to it
+type Game struct {
+	// Positions[i] is the board before Moves[i], the last one is the current board
+	Positions []Board
+	Moves []Move
+	Tags map[string]string
+	// Comments holds PGN comments by the index of the position they describe
+	Comments map[int]string
+	Result GameResult
+	Termination string
+}
+
+func NewGame(start Board) *Game {
+	return &Game{
+		Positions: []Board{start},
+		Tags: make(map[string]string),
+		Comments: make(map[int]string),
+		Result: ResultNone,
+	}
+}
+
+func (g *Game) Start() *Board {
+	return &g.Positions[0]
+}
+
+func (g *Game) Board() *Board {
+	return &g.Positions[len(g.Positions) - 1]
+}
+
+func (g *Game) Play(m Move) {
+	g.Positions = append(g.Positions, *g.Board().Apply(m))
+	g.Moves = append(g.Moves, m)
+	g.Result, g.Termination = g.detectResult()
+}
+
+// Resign ends the game as a loss for side, for reason
+func (g *Game) Resign(side Side, reason string) {
+	g.Result = winResult(1 - side)
+	g.Termination = reason
+}
+
+func (g *Game) Adjudicate(result GameResult, reason string) {
+	g.Result = result
+	g.Termination = reason
+}
+
+func winResult(side Side) GameResult {
+	if side == SideWhite {
+		return ResultWhiteWins
+	}
+	return ResultBlackWins
+}
+
+func (g *Game) detectResult() (GameResult, string) {
+	board := g.Board()
+	switch {
+	case board.Winner != SideNone:
+		return winResult(board.Winner), "king captured"
+	case board.IsCheckmate():
+		return winResult(1 - board.Turn), "checkmate"
+	case board.HalfmoveClock >= 100:
+		return ResultDraw, "fifty-move rule"
+	case g.Repetitions() >= 3:
+		return ResultDraw, "threefold repetition"
+	default:
+		return ResultNone, ""
+	}
+}
+
+// Repetitions counts how many times the current position has occurred
+func (g *Game) Repetitions() int {
+	hash := g.Board().Hash()
+	result := 0
+	for i := len(g.Positions) - 1; i >= 0; i-- {
+		if g.Positions[i].Hash() == hash {
+			result++
+		}
+		if g.Positions[i].HalfmoveClock == 0 {
+			break
+		}
+	}
+	return result
+}
+
+// SAN returns the i-th move of the game in SAN
+func (g *Game) SAN(i int) string {
+	return g.Positions[i].SAN(g.Moves[i])
+}
+
+// MoveNumber formats the number prefixing the i-th move, like "12." or "12..."
+func (g *Game) MoveNumber(i int) string {
+	board := &g.Positions[i]
+	if board.Turn == SideWhite {
+		return fmt.Sprintf("%d.", board.FullmoveNumber)
+	}
+	return fmt.Sprintf("%d...", board.FullmoveNumber)
+}
@@ -0,0 +1,105 @@
+package iosystem
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+type animatedPiece struct {
+	piece chess2.Piece
+	fromX, fromY, toX, toY int
+}
+
+type animation struct {
+	start float64
+	// moving pieces slide to their target squares, removed ones fade out
+	moving, removed []animatedPiece
+}
+
+func newAnimation(before *chess2.Board, m chess2.Move) *animation {
+	result := &animation{start: rl.GetTime()}
+	result.moving = append(result.moving, animatedPiece{*before.At(m.X1, m.Y1), m.X1, m.Y1, m.X2, m.Y2})
+
+	switch {
+	case before.WillBeEnPassant(m):
+		result.removed = append(result.removed, animatedPiece{*before.At(m.X2, m.Y1), m.X2, m.Y1, m.X2, m.Y1})
+	case before.WillBeCastle(m):
+		direction := chess2.Sign(m.X2 - m.X1)
+		rookX := 0
+		if direction > 0 {
+			rookX = chess2.BoardSize - 1
+		}
+		result.moving = append(result.moving, animatedPiece{
+			*before.At(rookX, m.Y1), rookX, m.Y1, m.X2 - direction, m.Y2,
+		})
+	case *before.At(m.X2, m.Y2) != chess2.PieceNone:
+		result.removed = append(result.removed, animatedPiece{*before.At(m.X2, m.Y2), m.X2, m.Y2, m.X2, m.Y2})
+	}
+	return result
+}
+
+// updateAnimation starts animating when the shown position advances by one move
+func (w *Window) updateAnimation(game *chess2.Game) {
+	ply := w.currentPly(game)
+	if ply != w.animatedPly {
+		w.animation = nil
+		if ply == w.animatedPly + 1 && !w.skipAnimation && w.options.AnimationDuration > 0 {
+			w.animation = newAnimation(&game.Positions[ply - 1], game.Moves[ply - 1])
+		}
+		w.animatedPly = ply
+		w.skipAnimation = false
+	}
+
+	if w.animation != nil && w.animationProgress() >= 1 {
+		w.animation = nil
+	}
+}
+
+func (w *Window) isAnimating() bool {
+	return w.animation != nil
+}
+
+func (w *Window) animationProgress() float32 {
+	return float32((rl.GetTime() - w.animation.start) / w.options.AnimationDuration.Seconds())
+}
+
+// isAnimatedTarget reports whether a piece is still sliding to the square,
+// so the board should not draw it there yet
+func (w *Window) isAnimatedTarget(x, y int) bool {
+	if w.animation == nil {
+		return false
+	}
+	for _, p := range w.animation.moving {
+		if p.toX == x && p.toY == y {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Window) drawAnimation() {
+	if w.animation == nil {
+		return
+	}
+
+	t := min(w.animationProgress(), 1)
+	for _, p := range w.animation.removed {
+		rl.DrawTexture(
+			w.resources.pieceSprites[p.piece].texture,
+			int32(p.fromX * w.layout.totalCellSize), int32(p.fromY * w.layout.totalCellSize),
+			rl.Fade(rl.White, 1 - t),
+		)
+	}
+
+	// ease out cubic
+	eased := 1 - (1 - t) * (1 - t) * (1 - t)
+	for _, p := range w.animation.moving {
+		x := float32(p.fromX) + float32(p.toX - p.fromX) * eased
+		y := float32(p.fromY) + float32(p.toY - p.fromY) * eased
+		rl.DrawTexture(
+			w.resources.pieceSprites[p.piece].texture,
+			int32(x * float32(w.layout.totalCellSize)), int32(y * float32(w.layout.totalCellSize)),
+			rl.White,
+		)
+	}
+}
@@ -0,0 +1,170 @@
+package iosystem
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/theme"
+)
+
+// annotationColor indexes theme.AnnotationColors and theme.AnnotationLetters
+type annotationColor int
+const (
+	annotationGreen annotationColor = iota
+	annotationRed
+	annotationBlue
+	annotationYellow
+)
+
+// annotation is an arrow drawn with the right mouse button, or a square mark
+// when both ends are the same square
+type annotation struct {
+	x1, y1, x2, y2 int
+	color annotationColor
+}
+
+func (a annotation) isMark() bool {
+	return a.x1 == a.x2 && a.y1 == a.y2
+}
+
+// modifierColor picks the colour by the held modifier key, like on lichess
+func modifierColor() annotationColor {
+	switch {
+	case rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift): return annotationRed
+	case rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl): return annotationBlue
+	case rl.IsKeyDown(rl.KeyLeftAlt) || rl.IsKeyDown(rl.KeyRightAlt): return annotationYellow
+	default: return annotationGreen
+	}
+}
+
+// toggleAnnotation removes an annotation of the same colour between the same
+// squares, recolours one of a different colour or adds a new one
+func (w *Window) toggleAnnotation(a annotation) {
+	i := slices.IndexFunc(w.annotations, func(other annotation) bool {
+		return other.x1 == a.x1 && other.y1 == a.y1 && other.x2 == a.x2 && other.y2 == a.y2
+	})
+	switch {
+	case i < 0: w.annotations = append(w.annotations, a)
+	case w.annotations[i].color == a.color: w.annotations = slices.Delete(w.annotations, i, i + 1)
+	default: w.annotations[i].color = a.color
+	}
+}
+
+func squareName(x, y int) string {
+	return fmt.Sprintf("%c%d", 'a' + x, chess2.BoardSize - y)
+}
+
+// annotationComment formats the annotations as PGN [%csl] and [%cal] commands
+func (w *Window) annotationComment() string {
+	var squares, arrows []string
+	for _, a := range w.annotations {
+		letter := string(theme.AnnotationLetters[a.color])
+		if a.isMark() {
+			squares = append(squares, letter + squareName(a.x1, a.y1))
+		} else {
+			arrows = append(arrows, letter + squareName(a.x1, a.y1) + squareName(a.x2, a.y2))
+		}
+	}
+
+	var result strings.Builder
+	if len(squares) > 0 {
+		fmt.Fprintf(&result, "[%%csl %s]", strings.Join(squares, ","))
+	}
+	if len(arrows) > 0 {
+		fmt.Fprintf(&result, "[%%cal %s]", strings.Join(arrows, ","))
+	}
+	return result.String()
+}
+
+// readAnnotationInput handles the right mouse button on the live position;
+// annotations are stored in the game as comments and cleared by the next move
+func (w *Window) readAnnotationInput(game *chess2.Game) {
+	if ply := len(game.Positions) - 1; ply != w.annotatedPly {
+		w.annotations = nil
+		w.annotatedPly = ply
+		w.annotating = false
+	}
+
+	if w.isViewingHistory() {
+		w.annotating = false
+		return
+	}
+
+	x, y := w.layout.mouseSquare()
+	onBoard := x < chess2.BoardSize && y < chess2.BoardSize
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonRight) && w.mode == selectionModeNone && onBoard {
+		w.annotating = true
+		w.annotationX = x
+		w.annotationY = y
+	}
+
+	if rl.IsMouseButtonReleased(rl.MouseButtonRight) && w.annotating {
+		w.annotating = false
+		if !onBoard {
+			return
+		}
+
+		w.toggleAnnotation(annotation{w.annotationX, w.annotationY, x, y, modifierColor()})
+		if comment := w.annotationComment(); comment != "" {
+			game.Comments[w.annotatedPly] = comment
+		} else {
+			delete(game.Comments, w.annotatedPly)
+		}
+	}
+}
+
+func (w *Window) squareCenter(x, y int) rl.Vector2 {
+	return rl.NewVector2(
+		float32(x * w.layout.totalCellSize + w.layout.totalCellSize / 2),
+		float32(y * w.layout.totalCellSize + w.layout.totalCellSize / 2),
+	)
+}
+
+func (w *Window) drawArrow(a annotation) {
+	from := w.squareCenter(a.x1, a.y1)
+	to := w.squareCenter(a.x2, a.y2)
+	dx, dy := to.X - from.X, to.Y - from.Y
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	dx, dy = dx / length, dy / length
+
+	base := rl.NewVector2(to.X - dx * w.layout.arrowHeadLength, to.Y - dy * w.layout.arrowHeadLength)
+	color := rl.Color(theme.AnnotationColors[a.color])
+	rl.DrawLineEx(from, base, w.layout.arrowWidth, color)
+	rl.DrawTriangle(
+		to,
+		rl.NewVector2(base.X + dy * w.layout.arrowHeadWidth / 2, base.Y - dx * w.layout.arrowHeadWidth / 2),
+		rl.NewVector2(base.X - dy * w.layout.arrowHeadWidth / 2, base.Y + dx * w.layout.arrowHeadWidth / 2),
+		color,
+	)
+}
+
+func (w *Window) drawAnnotation(a annotation) {
+	if a.isMark() {
+		radius := float32(w.layout.totalCellSize) / 2
+		rl.DrawRing(w.squareCenter(a.x1, a.y1), radius - w.layout.markWidth, radius, 0, 360, 32, rl.Color(theme.AnnotationColors[a.color]))
+	} else {
+		w.drawArrow(a)
+	}
+}
+
+func (w *Window) drawAnnotations() {
+	if w.isViewingHistory() {
+		return
+	}
+
+	for _, a := range w.annotations {
+		w.drawAnnotation(a)
+	}
+
+	if w.annotating {
+		x, y := w.layout.mouseSquare()
+		if x < chess2.BoardSize && y < chess2.BoardSize {
+			w.drawAnnotation(annotation{w.annotationX, w.annotationY, x, y, modifierColor()})
+		}
+	}
+}
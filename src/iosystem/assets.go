@@ -0,0 +1,165 @@
+package iosystem
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/girvel/chess2/src/theme"
+)
+
+type sprite struct {
+	// source is the image at its original resolution, texture is it scaled to
+	// the current square size
+	source *rl.Image
+	texture rl.Texture2D
+}
+
+func (s *sprite) rescale(cellSize int) {
+	if s.texture.ID != 0 {
+		rl.UnloadTexture(s.texture)
+	}
+	image := rl.ImageCopy(s.source)
+	defer rl.UnloadImage(image)
+	rl.ImageResizeNN(
+		image,
+		s.source.Width * int32(cellSize) / int32(spriteSize),
+		s.source.Height * int32(cellSize) / int32(spriteSize),
+	)
+	s.texture = rl.LoadTextureFromImage(image)
+}
+
+func (s *sprite) unload() {
+	rl.UnloadTexture(s.texture)
+	rl.UnloadImage(s.source)
+}
+
+type sounds struct {
+	move, capture, castle, promotion, check, win, loss rl.Sound
+}
+
+// resources are the sprites of the current theme and the sounds, loaded
+// from assets or, for sprites, from spriteDir when it is set
+type resources struct {
+	assets fs.FS
+	spriteDir string
+	// cellSize is the square size the sprites are scaled to
+	cellSize int
+
+	// sprites holds every loaded sprite, to rescale and unload them
+	sprites []*sprite
+	// pieceSprites are indexed by the piece, PieceNone is a blank square
+	pieceSprites []*sprite
+	moveSprite, moveSuggestedSprite, winSprite, lossSprite *sprite
+
+	sounds sounds
+}
+
+func (r *resources) loadImage(name string) *rl.Image {
+	data, err := theme.ReadAsset(theme.SpriteAssets(r.assets, r.spriteDir), name)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "%s", err)
+		return rl.GenImageColor(spriteSize, spriteSize, rl.Blank)
+	}
+	return rl.LoadImageFromMemory(filepath.Ext(name), data, int32(len(data)))
+}
+
+// newSprite takes ownership of the image
+func (r *resources) newSprite(image *rl.Image) *sprite {
+	result := &sprite{source: image}
+	r.sprites = append(r.sprites, result)
+	if r.cellSize > 0 {
+		result.rescale(r.cellSize)
+	}
+	return result
+}
+
+func (r *resources) loadSprite(name string) *sprite {
+	return r.newSprite(r.loadImage(name))
+}
+
+// loadSpriteColored loads the sprite with the from colour replaced by to
+func (r *resources) loadSpriteColored(name string, from, to rl.Color) *sprite {
+	image := r.loadImage(name)
+	rl.ImageColorReplace(image, from, to)
+	return r.newSprite(image)
+}
+
+// loadSprites replaces the sprites with the theme's, or with the ones in
+// spriteDir when it is set
+func (r *resources) loadSprites(t theme.Theme) {
+	r.unloadSprites()
+	if r.spriteDir != "" {
+		t.Sprites = r.spriteDir
+		t.WhiteSprites = ""
+		t.BlackSprites = ""
+	}
+
+	whiteDir := t.WhiteSprites
+	if whiteDir == "" {
+		whiteDir = t.Sprites
+	}
+
+	loadPiece := func(name string) (*sprite, *sprite) {
+		white := r.loadSprite(filepath.Join(whiteDir, name))
+		if t.BlackSprites != "" {
+			return white, r.loadSprite(filepath.Join(t.BlackSprites, name))
+		}
+		return white, r.loadSpriteColored(filepath.Join(whiteDir, name), rl.Color(t.WhitePiece), rl.Color(t.BlackPiece))
+	}
+
+	r.pieceSprites = []*sprite{r.loadSprite(filepath.Join(t.Sprites, "none.png"))}
+	for _, name := range []string{"pawn.png", "knight.png", "bishop.png", "rook.png", "queen.png", "king.png"} {
+		white, black := loadPiece(name)
+		r.pieceSprites = append(r.pieceSprites, white, black)
+	}
+
+	r.moveSprite = r.loadSprite(filepath.Join(t.Sprites, "move.png"))
+	r.moveSuggestedSprite = r.loadSprite(filepath.Join(t.Sprites, "move_suggested.png"))
+	r.winSprite = r.loadSprite(filepath.Join(t.Sprites, "win.png"))
+	r.lossSprite = r.loadSprite(filepath.Join(t.Sprites, "loss.png"))
+}
+
+func (r *resources) rescaleSprites(cellSize int) {
+	r.cellSize = cellSize
+	for _, s := range r.sprites {
+		s.rescale(cellSize)
+	}
+}
+
+func (r *resources) unloadSprites() {
+	for _, s := range r.sprites {
+		s.unload()
+	}
+	r.sprites = nil
+}
+
+func (r *resources) loadSound(name string) rl.Sound {
+	data, err := theme.ReadAsset(r.assets, name)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "%s", err)
+		return rl.Sound{}
+	}
+	wave := rl.LoadWaveFromMemory(filepath.Ext(name), data, int32(len(data)))
+	defer rl.UnloadWave(wave)
+	return rl.LoadSoundFromWave(wave)
+}
+
+func (r *resources) loadSounds() {
+	r.sounds = sounds{
+		move: r.loadSound("sounds/move.wav"),
+		capture: r.loadSound("sounds/capture.wav"),
+		castle: r.loadSound("sounds/castle.wav"),
+		promotion: r.loadSound("sounds/promotion.wav"),
+		check: r.loadSound("sounds/check.wav"),
+		win: r.loadSound("sounds/win.wav"),
+		loss: r.loadSound("sounds/loss.wav"),
+	}
+}
+
+func (r *resources) unloadSounds() {
+	s := r.sounds
+	for _, sound := range []rl.Sound{s.move, s.capture, s.castle, s.promotion, s.check, s.win, s.loss} {
+		rl.UnloadSound(sound)
+	}
+}
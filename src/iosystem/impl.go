@@ -1,128 +1,229 @@
 package iosystem
 
 import (
+	"fmt"
+	"io/fs"
+	"time"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/frontend"
+	"github.com/girvel/chess2/src/theme"
 )
 
-const scale int = 6
-const cellSize int = 16
-const totalCellSize int = scale * cellSize
-const windowSize = chess2.BoardSize * totalCellSize
-
-var colorWhiteSquare rl.Color = rl.GetColor(0xedededff)
-var colorBlackSquare rl.Color = rl.GetColor(0x3a373dff)
-var colorWhitePiece rl.Color = rl.GetColor(0xedededff)
-var colorBlackPiece rl.Color = rl.GetColor(0x544747ff)
-var colorSelected rl.Color = rl.GetColor(0xcfa867ff)
-var colorLastMoveDark rl.Color = rl.GetColor(0x5d863fff)
-var colorLastMoveLight rl.Color = rl.GetColor(0x869d42ff)
-
-var pieceSprites []rl.Texture2D
-var moveSprite, moveSuggestedSprite, winSprite, lossSprite rl.Texture2D
+var colorMessageBackground rl.Color = rl.GetColor(0x3a373dcc)
+var colorMessage rl.Color = rl.GetColor(0xedededff)
 
 type selectionMode int
 const (
 	selectionModeNone selectionMode = iota
 	selectionModeDrag
 	selectionModeSelect
+	selectionModePromotion
 )
 
-var selectedX, selectedY int
-var potentialMoves []chess2.Move
-var mode = selectionModeNone
+type Options struct {
+	// AnimationDuration is how long a move slides across the board, zero
+	// turns animations off
+	AnimationDuration time.Duration
+	// Volume is the initial sound volume from 0 to 1
+	Volume float32
+	// Theme is the name of the theme to start with
+	Theme string
+	// SpriteDir replaces the sprite directory of every theme when set
+	SpriteDir string
+	// Assets holds the sprites, sounds and themes; the disk is only read for
+	// SpriteDir or when Assets is nil
+	Assets fs.FS
+}
+
+func DefaultOptions() Options {
+	return Options{
+		AnimationDuration: 250 * time.Millisecond,
+		Volume: 0.8,
+		Theme: "default",
+	}
+}
+
+// Window is the raylib front-end and owns its layout, colours, sprites and
+// sounds; raylib has a single window, so there should be only one at a time
+type Window struct {
+	options Options
+	layout layout
+	palette palette
+	resources resources
+	themes []theme.Theme
+	themeIndex int
+
+	selectedX, selectedY int
+	potentialMoves []chess2.Move
+	mode selectionMode
+	// promotionMove is the pawn move waiting for a piece in selectionModePromotion
+	promotionMove chess2.Move
+	// promotionDragged is set when the pawn was dragged to the last rank
+	promotionDragged bool
+
+	showPanel bool
+	showCoordinates bool
+	showMoveDots bool
+	showHintLines bool
+
+	// viewedPly is the index of the position shown instead of the live game,
+	// -1 when the live game is shown
+	viewedPly int
+	moveListScroll int
+	moveListLength int
+	moveListSAN []string
 
-func Init() {
-	rl.InitWindow(int32(windowSize), int32(windowSize), "girvel's chess app")
+	annotations []annotation
+	annotatedPly int
+	annotating bool
+	annotationX, annotationY int
+
+	animation *animation
+	animatedPly int
+	// skipAnimation is set for moves the user dragged, which are already in place
+	skipAnimation bool
+
+	volume float32
+	muted bool
+	soundedPly int
+
+	// typing is set while the move text box has the keyboard
+	typing bool
+	moveText string
+	moveTextError string
+	showCursor bool
+	cursorX, cursorY int
+}
+
+var _ frontend.Frontend = (*Window)(nil)
+
+func New(options Options) *Window {
+	return &Window{
+		options: options,
+		resources: resources{assets: options.Assets, spriteDir: options.SpriteDir},
+		showPanel: true,
+		showCoordinates: true,
+		showMoveDots: true,
+		viewedPly: -1,
+		volume: options.Volume,
+		cursorX: 4,
+		cursorY: chess2.BoardSize - 2,
+	}
+}
+
+func (w *Window) Init() {
+	rl.SetConfigFlags(rl.FlagWindowResizable)
+	rl.InitWindow(
+		int32(chess2.BoardSize + panelCells) * designCellSize, int32(chess2.BoardSize) * designCellSize,
+		"girvel's chess app",
+	)
+	rl.SetWindowMinSize(chess2.BoardSize * minCellSize, chess2.BoardSize * minCellSize)
 	rl.SetTargetFPS(60)
+	w.updateLayout()
+	w.initSound()
 
-	pieceSprites = []rl.Texture2D{
-		loadSprite("sprites/none.png"),
-		loadSprite("sprites/pawn.png"),
-		loadSpriteColored("sprites/pawn.png"),
-		loadSprite("sprites/knight.png"),
-		loadSpriteColored("sprites/knight.png"),
-		loadSprite("sprites/bishop.png"),
-		loadSpriteColored("sprites/bishop.png"),
-		loadSprite("sprites/rook.png"),
-		loadSpriteColored("sprites/rook.png"),
-		loadSprite("sprites/queen.png"),
-		loadSpriteColored("sprites/queen.png"),
-		loadSprite("sprites/king.png"),
-		loadSpriteColored("sprites/king.png"),
-	}
-
-	moveSprite = loadSprite("sprites/move.png")
-	moveSuggestedSprite = loadSprite("sprites/move_suggested.png")
-	winSprite = loadSprite("sprites/win.png")
-	lossSprite = loadSprite("sprites/loss.png")
+	w.initThemes(w.options.Theme)
 }
 
-func Draw(board *chess2.Board) {
+func (w *Window) updateLayout() {
+	if w.layout.update(w.showPanel) {
+		w.resources.rescaleSprites(w.layout.totalCellSize)
+	}
+}
+
+// Draw renders the board and the side panel
+func (w *Window) Draw(state *frontend.State) {
+	game := state.Game
+	w.updateLayout()
 	rl.BeginDrawing()
+	rl.ClearBackground(w.palette.panel)
+	board := w.shownBoard(game)
+	w.updateAnimation(game)
+	w.updateSound(game)
+	hints := w.hints(state)
 
 	for x := range chess2.BoardSize {
 		for y := range chess2.BoardSize {
 			var squareColor rl.Color
 			switch {
-			case mode != selectionModeNone && x == selectedX && y == selectedY:
-				squareColor = colorSelected
+			case w.mode != selectionModeNone && x == w.selectedX && y == w.selectedY:
+				squareColor = w.palette.selected
+			case len(hints) > 0 && (
+				x == hints[0].Move.X1 && y == hints[0].Move.Y1 ||
+				x == hints[0].Move.X2 && y == hints[0].Move.Y2):
+				squareColor = w.palette.hint
+			case isKingAttacked(board, x, y):
+				squareColor = w.palette.check
 			case (x + y) % 2 == 0:
 				if board.LastMove != nil && (
 					x == board.LastMove.X1 && y == board.LastMove.Y1 ||
 					x == board.LastMove.X2 && y == board.LastMove.Y2) {
-					squareColor = colorLastMoveLight
+					squareColor = w.palette.lastMoveLight
 				} else {
-					squareColor = colorWhiteSquare
+					squareColor = w.palette.whiteSquare
 				}
 			default:
 				if board.LastMove != nil && (
 					x == board.LastMove.X1 && y == board.LastMove.Y1 ||
 					x == board.LastMove.X2 && y == board.LastMove.Y2) {
-					squareColor = colorLastMoveDark
+					squareColor = w.palette.lastMoveDark
 				} else {
-					squareColor = colorBlackSquare
+					squareColor = w.palette.blackSquare
 				}
 			}
 
-			renderX := int32(x * totalCellSize)
-			renderY := int32(y * totalCellSize)
+			renderX := int32(x * w.layout.totalCellSize)
+			renderY := int32(y * w.layout.totalCellSize)
 			rl.DrawRectangle(
 				renderX, renderY,
-				int32(totalCellSize), int32(totalCellSize),
+				int32(w.layout.totalCellSize), int32(w.layout.totalCellSize),
 				squareColor,
 			)
+			if w.showCoordinates {
+				w.drawCoordinates(x, y)
+			}
 			
 			piece := *board.At(x, y)
 			if piece != chess2.PieceNone &&
-				(mode != selectionModeDrag || x != selectedX || y != selectedY) {
-				rl.DrawTexture(pieceSprites[piece], renderX, renderY, rl.White)
+				(w.mode != selectionModeDrag || x != w.selectedX || y != w.selectedY) &&
+				!w.isAnimatedTarget(x, y) {
+				rl.DrawTexture(w.resources.pieceSprites[piece].texture, renderX, renderY, rl.White)
 			}
 		}
 	}
 
-	hoverX := int(rl.GetMouseX()) / totalCellSize
-	hoverY := int(rl.GetMouseY()) / totalCellSize
+	w.drawAnimation()
+
+	hoverX, hoverY := w.layout.mouseSquare()
 
-	if mode != selectionModeNone {
-		for _, m := range potentialMoves {
-			texture := moveSprite
+	if w.showMoveDots && (w.mode == selectionModeDrag || w.mode == selectionModeSelect) {
+		for _, m := range w.potentialMoves {
+			texture := w.resources.moveSprite
 			if hoverX == m.X2 && hoverY == m.Y2 {
-				texture = moveSuggestedSprite
+				texture = w.resources.moveSuggestedSprite
 			}
 
 			rl.DrawTexture(
-				texture,
-				int32(m.X2 * totalCellSize), int32(m.Y2 * totalCellSize),
+				texture.texture,
+				int32(m.X2 * w.layout.totalCellSize), int32(m.Y2 * w.layout.totalCellSize),
 				rl.White,
 			)
 		}
 	}
 
-	if mode == selectionModeDrag {
+	w.drawAnnotations()
+
+	if w.mode == selectionModePromotion {
+		w.drawPromotion(board, hoverX, hoverY)
+	}
+	w.drawCursor()
+
+	if w.mode == selectionModeDrag {
 		rl.DrawTexture(
-			pieceSprites[*board.At(selectedX, selectedY)],
-			rl.GetMouseX() - int32(totalCellSize) / 2, rl.GetMouseY() - int32(totalCellSize) / 2,
+			w.resources.pieceSprites[*board.At(w.selectedX, w.selectedY)].texture,
+			rl.GetMouseX() - int32(w.layout.totalCellSize) / 2, rl.GetMouseY() - int32(w.layout.totalCellSize) / 2,
 			rl.White,
 		)
 	}
@@ -130,92 +231,243 @@ func Draw(board *chess2.Board) {
 	if board.Winner != chess2.SideNone {
 		var texture rl.Texture2D
 		switch board.Winner {
-		case chess2.SideWhite: texture = winSprite
-		case chess2.SideBlack: texture = lossSprite
+		case chess2.SideWhite: texture = w.resources.winSprite.texture
+		case chess2.SideBlack: texture = w.resources.lossSprite.texture
 		}
 
 		rl.DrawTexture(
 			texture,
-			(int32(windowSize) - texture.Width) / 2, (int32(windowSize) - texture.Height) / 2,
+			(int32(w.layout.boardSize) - texture.Width) / 2, (int32(w.layout.boardSize) - texture.Height) / 2,
 			rl.White,
 		)
+	} else if state.Analysis.Mate > 0 {
+		text := fmt.Sprintf("mate in %d", state.Analysis.Mate)
+		width := rl.MeasureText(text, w.layout.messageFontSize)
+		rl.DrawRectangle(
+			(int32(w.layout.boardSize) - width) / 2 - w.layout.messagePadding, 0,
+			width + 2 * w.layout.messagePadding, w.layout.messageFontSize + 2 * w.layout.messagePadding,
+			colorMessageBackground,
+		)
+		rl.DrawText(text, (int32(w.layout.boardSize) - width) / 2, w.layout.messagePadding, w.layout.messageFontSize, colorMessage)
 	}
+
+	if w.showHintLines && len(hints) > 0 {
+		lines := make([]string, len(hints))
+		for i, hint := range hints {
+			lines[i] = fmt.Sprintf("%d. %s %s", i + 1, board.SAN(hint.Move), formatScore(hint.SearchInfo))
+		}
+		w.drawMessageBox(lines, 0, int32(w.layout.boardSize))
+	}
+
+	if state.HintsUsed > 0 {
+		text := fmt.Sprintf("hints: %d", state.HintsUsed)
+		w.drawMessageBox([]string{text}, int32(w.layout.boardSize) - rl.MeasureText(text, w.layout.messageFontSize) - 2 * w.layout.messagePadding, int32(w.layout.boardSize))
+	}
+
+	w.drawMoveText()
+	w.drawPanel(game, state.Analysis)
 	rl.EndDrawing()
 }
 
-func ReadInput(board *chess2.Board) (*chess2.Move, bool) {
-	shouldClose := rl.WindowShouldClose()
-	if board.Winner != chess2.SideNone || board.Turn == chess2.SideBlack {
-		return nil, shouldClose
+// drawCoordinates labels the files along the bottom rank and the ranks along
+// the a-file, in the colour of the opposite squares
+func (w *Window) drawCoordinates(x, y int) {
+	color := w.palette.whiteSquare
+	if (x + y) % 2 == 0 {
+		color = w.palette.blackSquare
+	}
+
+	renderX := int32(x * w.layout.totalCellSize)
+	renderY := int32(y * w.layout.totalCellSize)
+	if x == 0 {
+		rank := fmt.Sprint(chess2.BoardSize - y)
+		rl.DrawText(rank, renderX + w.layout.coordinatePadding, renderY + w.layout.coordinatePadding, w.layout.coordinateFontSize, color)
+	}
+	if y == chess2.BoardSize - 1 {
+		file := string(rune('a' + x))
+		rl.DrawText(
+			file,
+			renderX + int32(w.layout.totalCellSize) - rl.MeasureText(file, w.layout.coordinateFontSize) - w.layout.coordinatePadding,
+			renderY + int32(w.layout.totalCellSize) - w.layout.coordinateFontSize - w.layout.coordinatePadding,
+			w.layout.coordinateFontSize, color,
+		)
+	}
+}
+
+// isKingAttacked reports whether there is a king at the square that its
+// opponent could capture
+func isKingAttacked(board *chess2.Board, x, y int) bool {
+	piece := *board.At(x, y)
+	if piece != chess2.PieceWhiteKing && piece != chess2.PieceBlackKing {
+		return false
+	}
+
+	return board.IsAttackedBy(1 - piece.Side(), x, y)
+}
+
+// drawMessageBox draws lines of text in a box with its bottom-left corner at (x, bottom)
+func (w *Window) drawMessageBox(lines []string, x, bottom int32) {
+	var width int32
+	for _, line := range lines {
+		width = max(width, rl.MeasureText(line, w.layout.messageFontSize))
+	}
+	height := int32(len(lines)) * w.layout.messageFontSize + 2 * w.layout.messagePadding
+
+	rl.DrawRectangle(x, bottom - height, width + 2 * w.layout.messagePadding, height, colorMessageBackground)
+	for i, line := range lines {
+		rl.DrawText(
+			line, x + w.layout.messagePadding, bottom - height + w.layout.messagePadding + int32(i) * w.layout.messageFontSize,
+			w.layout.messageFontSize, colorMessage,
+		)
+	}
+}
+
+func formatScore(info chess2.SearchInfo) string {
+	if info.Mate != 0 {
+		return fmt.Sprintf("#%d", info.Mate)
+	}
+	return fmt.Sprintf("%+.2f", info.Score)
+}
+
+// hints are the suggested moves to highlight, only shown on the live position
+func (w *Window) hints(state *frontend.State) []chess2.SearchResult {
+	if w.isViewingHistory() {
+		return nil
 	}
+	return state.Hints
+}
 
-	x := int(rl.GetMouseX()) / totalCellSize
-	y := int(rl.GetMouseY()) / totalCellSize
+func (w *Window) ReadInput(state *frontend.State) frontend.Input {
+	game := state.Game
+	input := frontend.Input{ShouldClose: rl.WindowShouldClose(), Animating: w.isAnimating()}
+	// the hotkeys are letters too, so they are off while a move is typed
+	var cursorSubmitted bool
+	if w.typing {
+		w.readMoveText(game, &input)
+	} else {
+		if rl.IsKeyPressed(rl.KeyTab) {
+			w.showPanel = !w.showPanel
+		}
+		if rl.IsKeyPressed(rl.KeyC) {
+			w.showCoordinates = !w.showCoordinates
+		}
+		if rl.IsKeyPressed(rl.KeyD) {
+			w.showMoveDots = !w.showMoveDots
+		}
+		if rl.IsKeyPressed(rl.KeyT) {
+			w.cycleTheme()
+		}
+		if rl.IsKeyPressed(rl.KeySpace) {
+			w.startTyping()
+		}
+		w.readSoundInput()
+		cursorSubmitted = w.readCursorInput()
+	}
+	w.readMoveListInput(game)
+	w.readAnnotationInput(game)
+
+	board := game.Board()
+	if board.Winner != chess2.SideNone || board.Turn != frontend.HumanSide || w.isViewingHistory() || input.Animating {
+		return input
+	}
+
+	if rl.IsKeyPressed(rl.KeyH) && !w.typing {
+		if len(state.Hints) > 0 {
+			w.showHintLines = !w.showHintLines
+		} else {
+			input.Hint = true
+		}
+	}
+
+	x, y := w.layout.mouseSquare()
 
-	var submittedMove *chess2.Move
 	submitMove := func() {
-		mode = selectionModeNone
-		move := chess2.NewMove(selectedX, selectedY, x, y)
-		if board.IsMoveLegal(move) {
-			submittedMove = &move
+		dragged := w.mode == selectionModeDrag
+		w.mode = selectionModeNone
+		move := chess2.NewMove(w.selectedX, w.selectedY, x, y)
+		switch {
+		case !board.IsMoveLegal(move):
+		case board.IsPromotion(move):
+			w.promotionMove = move
+			w.promotionDragged = dragged
+			w.mode = selectionModePromotion
+		default:
+			input.Move = &move
+			w.skipAnimation = dragged
+		}
+	}
+
+	selectSquare := func(mode selectionMode) {
+		if x < chess2.BoardSize && y < chess2.BoardSize && board.At(x, y).Is(board.Turn) {
+			w.selectedX = x
+			w.selectedY = y
+			w.mode = mode
+			w.potentialMoves = board.GetMoves(x, y)
+		}
+	}
+
+	choosePromotion := func() {
+		w.mode = selectionModeNone
+		if piece := w.promotionAt(board, x, y); piece != chess2.PieceNone {
+			move := w.promotionMove
+			move.Promotion = piece
+			input.Move = &move
+			w.skipAnimation = w.promotionDragged
 		}
 	}
 
 	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
-		switch mode {
+		w.showCursor = false
+		switch w.mode {
 		case selectionModeNone:
-			if board.At(x, y).Is(board.Turn) {
-				selectedX = x
-				selectedY = y
-				mode = selectionModeDrag
-				potentialMoves = board.GetMoves(x, y)
-			}
+			selectSquare(selectionModeDrag)
 		case selectionModeSelect:
 			submitMove()
 		case selectionModeDrag:
-			if x == selectedX && y == selectedY {
-				mode = selectionModeSelect
+			if x == w.selectedX && y == w.selectedY {
+				w.mode = selectionModeSelect
 			}
+		case selectionModePromotion:
+			choosePromotion()
 		}
 	}
 
 	if rl.IsMouseButtonReleased(rl.MouseButtonLeft) {
-		switch mode {
+		switch w.mode {
 		case selectionModeDrag:
 			submitMove()
 		}
 	}
 
 	if rl.IsMouseButtonPressed(rl.MouseButtonRight) {
-		mode = selectionModeNone
+		w.mode = selectionModeNone
 	}
 
-	return submittedMove, shouldClose
-}
-
-func Deinit() {
-	for _, sprite := range pieceSprites {
-		rl.UnloadTexture(sprite)
+	// Enter on the keyboard cursor works like a click on its square
+	if cursorSubmitted {
+		x, y = w.cursorX, w.cursorY
+		switch w.mode {
+		case selectionModeNone:
+			selectSquare(selectionModeSelect)
+		case selectionModeSelect:
+			if x == w.selectedX && y == w.selectedY {
+				w.mode = selectionModeNone
+			} else {
+				submitMove()
+			}
+		case selectionModePromotion:
+			choosePromotion()
+		}
+		if w.mode == selectionModePromotion {
+			w.cursorX, w.cursorY = w.promotionSquare(0)
+		}
 	}
 
-	rl.UnloadTexture(moveSprite)
-	rl.UnloadTexture(moveSuggestedSprite)
-	rl.UnloadTexture(winSprite)
-	rl.UnloadTexture(lossSprite)
-	rl.CloseWindow()
+	return input
 }
 
-func loadSprite(filepath string) rl.Texture2D {
-	image := rl.LoadImage(filepath)
-	defer rl.UnloadImage(image)
-	rl.ImageResizeNN(image, image.Width * int32(scale), image.Height * int32(scale))
-	return rl.LoadTextureFromImage(image)
-}
-
-func loadSpriteColored(filepath string) rl.Texture2D {
-	image := rl.LoadImage(filepath)
-	defer rl.UnloadImage(image)
-	rl.ImageColorReplace(image, colorWhitePiece, colorBlackPiece)
-	rl.ImageResizeNN(image, image.Width * int32(scale), image.Height * int32(scale))
-	return rl.LoadTextureFromImage(image)
+func (w *Window) Deinit() {
+	w.resources.unloadSprites()
+	w.deinitSound()
+	rl.CloseWindow()
 }
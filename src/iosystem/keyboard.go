@@ -0,0 +1,135 @@
+package iosystem
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/frontend"
+)
+
+// maxMoveTextLength fits the longest SAN, like "exd8=Q+"
+const maxMoveTextLength = 8
+
+// startTyping gives the keyboard to the move text box; Escape closes the box
+// instead of the window until the box is closed
+func (w *Window) startTyping() {
+	w.typing = true
+	w.moveText = ""
+	w.moveTextError = ""
+	rl.SetExitKey(rl.KeyNull)
+}
+
+func (w *Window) stopTyping() {
+	w.typing = false
+	rl.SetExitKey(rl.KeyEscape)
+}
+
+func isEnterPressed() bool {
+	return rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter)
+}
+
+// readMoveText edits the text box and submits the move typed into it in
+// coordinate notation or SAN
+func (w *Window) readMoveText(game *chess2.Game, input *frontend.Input) {
+	for c := rl.GetCharPressed(); c != 0; c = rl.GetCharPressed() {
+		if c > ' ' && c < 127 && len(w.moveText) < maxMoveTextLength {
+			w.moveText += string(rune(c))
+			w.moveTextError = ""
+		}
+	}
+
+	switch {
+	case rl.IsKeyPressed(rl.KeyEscape):
+		w.stopTyping()
+	case rl.IsKeyPressed(rl.KeyBackspace) || rl.IsKeyPressedRepeat(rl.KeyBackspace):
+		if w.moveText == "" {
+			w.stopTyping()
+		} else {
+			w.moveText = w.moveText[:len(w.moveText) - 1]
+			w.moveTextError = ""
+		}
+	case isEnterPressed():
+		w.submitMoveText(game, input)
+	}
+}
+
+func (w *Window) submitMoveText(game *chess2.Game, input *frontend.Input) {
+	board := game.Board()
+	switch {
+	case w.moveText == "":
+		w.stopTyping()
+	case board.Winner != chess2.SideNone:
+		w.moveTextError = "the game is over"
+	case board.Turn != frontend.HumanSide:
+		w.moveTextError = "wait for your turn"
+	case w.isViewingHistory():
+		w.moveTextError = "press End to return to the game"
+	case input.Animating:
+		w.moveTextError = "wait for the move to finish"
+	default:
+		move, err := board.ParseAnyMove(w.moveText)
+		if err != nil {
+			w.moveTextError = err.Error()
+			return
+		}
+		input.Move = &move
+		w.mode = selectionModeNone
+		w.stopTyping()
+	}
+}
+
+// readCursorInput moves the keyboard cursor with the arrow keys and reports
+// whether Enter was pressed on it; the first key only shows the cursor
+func (w *Window) readCursorInput() bool {
+	isPressed := func(key int32) bool {
+		return rl.IsKeyPressed(key) || rl.IsKeyPressedRepeat(key)
+	}
+
+	dx, dy := 0, 0
+	switch {
+	case isPressed(rl.KeyLeft): dx = -1
+	case isPressed(rl.KeyRight): dx = 1
+	case isPressed(rl.KeyUp): dy = -1
+	case isPressed(rl.KeyDown): dy = 1
+	case isEnterPressed():
+		if w.showCursor {
+			return true
+		}
+	default:
+		return false
+	}
+
+	if w.showCursor {
+		w.cursorX = min(max(w.cursorX + dx, 0), chess2.BoardSize - 1)
+		w.cursorY = min(max(w.cursorY + dy, 0), chess2.BoardSize - 1)
+	}
+	w.showCursor = true
+	return false
+}
+
+func (w *Window) drawCursor() {
+	if !w.showCursor {
+		return
+	}
+
+	rl.DrawRectangleLinesEx(
+		rl.NewRectangle(
+			float32(w.cursorX * w.layout.totalCellSize), float32(w.cursorY * w.layout.totalCellSize),
+			float32(w.layout.totalCellSize), float32(w.layout.totalCellSize),
+		),
+		w.layout.cursorWidth, w.palette.hint,
+	)
+}
+
+// drawMoveText shows the text box in the top-left corner of the board, with
+// the reason the last typed move was rejected below it
+func (w *Window) drawMoveText() {
+	if !w.typing {
+		return
+	}
+
+	lines := []string{"move: " + w.moveText + "_"}
+	if w.moveTextError != "" {
+		lines = append(lines, w.moveTextError)
+	}
+	w.drawMessageBox(lines, 0, int32(len(lines)) * w.layout.messageFontSize + 2 * w.layout.messagePadding)
+}
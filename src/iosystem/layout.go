@@ -0,0 +1,89 @@
+package iosystem
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// designCellSize is the square size the layout sizes below are chosen for,
+// they are scaled with the squares when the window is resized
+const designCellSize int32 = 96
+const spriteSize int = 16
+const minCellSize int = 16
+const panelCells int = 4
+
+// layout is recomputed from the window size by update every frame
+type layout struct {
+	totalCellSize int
+	boardSize int
+	panelWidth int
+	messageFontSize, messagePadding int32
+	coordinateFontSize, coordinatePadding int32
+	panelPadding, panelFontSize, panelLineHeight, evalBarWidth int32
+	panelTextX, panelTextWidth, analysisHeight int32
+	moveListTop, moveListBottom, moveListNumberWidth, moveListColumnWidth int32
+	moveListVisibleRows int
+	arrowWidth, arrowHeadLength, arrowHeadWidth, markWidth, cursorWidth float32
+}
+
+func (l *layout) scaled(size int32) int32 {
+	return max(1, size * int32(l.totalCellSize) / designCellSize)
+}
+
+func (l *layout) scaledFont(size int32) int32 {
+	return max(10, l.scaled(size))
+}
+
+// update reports whether the square size has changed, so that the sprites
+// need rescaling
+func (l *layout) update(showPanel bool) bool {
+	columns := chess2.BoardSize
+	if showPanel {
+		columns += panelCells
+	}
+	cell := max(
+		min(rl.GetScreenWidth() / columns, rl.GetScreenHeight() / chess2.BoardSize),
+		minCellSize,
+	)
+	if cell == l.totalCellSize {
+		return false
+	}
+
+	l.totalCellSize = cell
+	l.boardSize = chess2.BoardSize * cell
+	l.panelWidth = panelCells * cell
+
+	l.messageFontSize = l.scaledFont(32)
+	l.messagePadding = l.scaled(12)
+	l.coordinateFontSize = l.scaledFont(18)
+	l.coordinatePadding = l.scaled(4)
+
+	l.panelPadding = l.scaled(12)
+	l.panelFontSize = l.scaledFont(20)
+	l.panelLineHeight = l.panelFontSize + l.panelFontSize / 4
+	l.evalBarWidth = l.scaled(24)
+	l.panelTextX = int32(l.boardSize) + 2 * l.panelPadding + l.evalBarWidth
+	l.panelTextWidth = int32(l.panelWidth) - 3 * l.panelPadding - l.evalBarWidth
+	l.analysisHeight = 2 * int32(cell)
+
+	l.moveListTop = l.analysisHeight + l.panelPadding
+	// the last line below the list is left for the history note
+	l.moveListBottom = int32(l.boardSize) - l.panelPadding - l.panelLineHeight
+	l.moveListNumberWidth = 2 * l.panelFontSize
+	l.moveListColumnWidth = (l.panelTextWidth - l.moveListNumberWidth) / 2
+	l.moveListVisibleRows = max(1, int((l.moveListBottom - l.moveListTop) / l.panelLineHeight))
+
+	l.arrowWidth = float32(l.scaled(16))
+	l.arrowHeadLength = float32(l.scaled(36))
+	l.arrowHeadWidth = float32(l.scaled(40))
+	l.markWidth = float32(l.scaled(8))
+	l.cursorWidth = float32(l.scaled(6))
+	return true
+}
+
+// mouseSquare returns the board square under the mouse, which may be outside
+// of the board
+func (l *layout) mouseSquare() (int, int) {
+	return int(rl.GetMouseX()) / l.totalCellSize, int(rl.GetMouseY()) / l.totalCellSize
+}
+
@@ -0,0 +1,145 @@
+package iosystem
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// moveListOffset is 1 when the game starts with black to move, so the first
+// row holds only a black move
+func moveListOffset(game *chess2.Game) int {
+	if game.Start().Turn == chess2.SideBlack {
+		return 1
+	}
+	return 0
+}
+
+func moveListRows(game *chess2.Game) int {
+	return (len(game.Moves) + moveListOffset(game) + 1) / 2
+}
+
+// currentPly is the index of the shown position in game.Positions
+func (w *Window) currentPly(game *chess2.Game) int {
+	if w.viewedPly < 0 {
+		return len(game.Positions) - 1
+	}
+	return w.viewedPly
+}
+
+func (w *Window) shownBoard(game *chess2.Game) *chess2.Board {
+	return &game.Positions[w.currentPly(game)]
+}
+
+func (w *Window) isViewingHistory() bool {
+	return w.viewedPly >= 0
+}
+
+func (w *Window) viewPly(game *chess2.Game, ply int) {
+	ply = max(ply, 0)
+	if ply >= len(game.Positions) - 1 {
+		w.viewedPly = -1
+	} else {
+		w.viewedPly = ply
+	}
+	w.mode = selectionModeNone
+
+	if row := (w.currentPly(game) - 1 + moveListOffset(game)) / 2;
+		row < w.moveListScroll || row >= w.moveListScroll + w.layout.moveListVisibleRows {
+		w.moveListScroll = row - w.layout.moveListVisibleRows / 2
+	}
+}
+
+// moveSAN caches the SAN of the game's moves, which never change once played
+func (w *Window) moveSAN(game *chess2.Game, i int) string {
+	for len(w.moveListSAN) <= i {
+		w.moveListSAN = append(w.moveListSAN, game.SAN(len(w.moveListSAN)))
+	}
+	return w.moveListSAN[i]
+}
+
+// moveAt returns the index of the move under the mouse or -1
+func (w *Window) moveAt(game *chess2.Game, mouseX, mouseY int32) int {
+	if !w.showPanel || mouseY < w.layout.moveListTop || mouseY >= w.layout.moveListBottom {
+		return -1
+	}
+
+	column := -1
+	switch x := mouseX - w.layout.panelTextX - w.layout.moveListNumberWidth; {
+	case x >= 0 && x < w.layout.moveListColumnWidth: column = 0
+	case x >= w.layout.moveListColumnWidth && x < 2 * w.layout.moveListColumnWidth: column = 1
+	}
+	if column < 0 {
+		return -1
+	}
+
+	row := w.moveListScroll + int((mouseY - w.layout.moveListTop) / w.layout.panelLineHeight)
+	i := row * 2 + column - moveListOffset(game)
+	if i < 0 || i >= len(game.Moves) {
+		return -1
+	}
+	return i
+}
+
+func (w *Window) readMoveListInput(game *chess2.Game) {
+	if rl.IsKeyPressed(rl.KeyPageUp) {
+		w.viewPly(game, w.currentPly(game) - 1)
+	}
+	if rl.IsKeyPressed(rl.KeyPageDown) {
+		w.viewPly(game, w.currentPly(game) + 1)
+	}
+	if rl.IsKeyPressed(rl.KeyHome) {
+		w.viewPly(game, 0)
+	}
+	if rl.IsKeyPressed(rl.KeyEnd) {
+		w.viewPly(game, len(game.Positions) - 1)
+	}
+
+	mouseX, mouseY := rl.GetMouseX(), rl.GetMouseY()
+	if mouseX >= int32(w.layout.boardSize) && mouseY >= w.layout.moveListTop {
+		w.moveListScroll -= int(rl.GetMouseWheelMove())
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+		if i := w.moveAt(game, mouseX, mouseY); i >= 0 {
+			w.viewPly(game, i + 1)
+		}
+	}
+
+	if len(game.Moves) != w.moveListLength {
+		w.moveListLength = len(game.Moves)
+		if !w.isViewingHistory() {
+			w.moveListScroll = moveListRows(game)
+		}
+	}
+	w.moveListScroll = max(0, min(w.moveListScroll, moveListRows(game) - w.layout.moveListVisibleRows))
+}
+
+func (w *Window) drawMoveList(game *chess2.Game) {
+	current := w.currentPly(game) - 1
+	for row := w.moveListScroll; row < min(moveListRows(game), w.moveListScroll + w.layout.moveListVisibleRows); row++ {
+		y := w.layout.moveListTop + int32(row - w.moveListScroll) * w.layout.panelLineHeight
+		number := game.Start().FullmoveNumber + row
+		rl.DrawText(fmt.Sprintf("%d.", number), w.layout.panelTextX, y, w.layout.panelFontSize, colorPanelDim)
+
+		for column := range 2 {
+			i := row * 2 + column - moveListOffset(game)
+			if i < 0 || i >= len(game.Moves) {
+				continue
+			}
+
+			x := w.layout.panelTextX + w.layout.moveListNumberWidth + int32(column) * w.layout.moveListColumnWidth
+			color := w.palette.panelText
+			if i == current {
+				rl.DrawRectangle(x - 4, y - 2, w.layout.moveListColumnWidth - 4, w.layout.panelLineHeight, w.palette.selected)
+				color = w.palette.panel
+			}
+			rl.DrawText(w.moveSAN(game, i), x, y, w.layout.panelFontSize, color)
+		}
+	}
+
+	if w.isViewingHistory() {
+		rl.DrawText("viewing history, End to return", w.layout.panelTextX, w.layout.moveListBottom, w.layout.panelFontSize, colorPanelDim)
+	}
+}
@@ -0,0 +1,126 @@
+package iosystem
+
+import (
+	"fmt"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+// evalScale is the score in pawns at which the bar is about three quarters full
+const evalScale float64 = 4
+
+var colorPanelDim rl.Color = rl.GetColor(0x8c878fff)
+var colorEvalWhite rl.Color = rl.GetColor(0xedededff)
+var colorEvalBlack rl.Color = rl.GetColor(0x544747ff)
+
+// whiteScore returns the analysis score and mate distance from white's point of view
+func whiteScore(analysis chess2.Analysis) (float64, int) {
+	if analysis.Root.Turn == chess2.SideBlack {
+		return -analysis.Score, -analysis.Mate
+	}
+	return analysis.Score, analysis.Mate
+}
+
+// evalFraction is the part of the eval bar filled with white
+func evalFraction(analysis chess2.Analysis) float32 {
+	score, mate := whiteScore(analysis)
+	switch {
+	case mate > 0: return 1
+	case mate < 0: return 0
+	}
+	return float32(1 / (1 + math.Exp(-score * math.Log(3) / evalScale)))
+}
+
+func formatEval(analysis chess2.Analysis) string {
+	score, mate := whiteScore(analysis)
+	switch {
+	case mate > 0: return fmt.Sprintf("#%d", mate)
+	case mate < 0: return fmt.Sprintf("#-%d", -mate)
+	}
+	return fmt.Sprintf("%+.2f", score)
+}
+
+// pvTokens renders the principal variation in numbered SAN, stopping at the
+// first move that is illegal in the root position
+func pvTokens(analysis chess2.Analysis) []string {
+	game := chess2.NewGame(analysis.Root)
+	var result []string
+	for i, m := range analysis.PV {
+		if !game.Board().IsMoveLegal(m) {
+			break
+		}
+		game.Play(m)
+
+		san := game.SAN(i)
+		if i == 0 || game.Positions[i].Turn == chess2.SideWhite {
+			san = game.MoveNumber(i) + " " + san
+		}
+		result = append(result, san)
+	}
+	return result
+}
+
+// wrapText joins tokens into lines no wider than width
+func wrapText(tokens []string, width, fontSize int32) []string {
+	var lines []string
+	var line string
+	for _, token := range tokens {
+		candidate := token
+		if line != "" {
+			candidate = line + " " + token
+		}
+		if line != "" && rl.MeasureText(candidate, fontSize) > width {
+			lines = append(lines, line)
+			candidate = token
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (w *Window) drawPanel(game *chess2.Game, analysis chess2.Analysis) {
+	if !w.showPanel {
+		return
+	}
+
+	left := int32(w.layout.boardSize)
+	height := int32(w.layout.boardSize)
+	rl.DrawRectangle(left, 0, int32(w.layout.panelWidth), height, w.palette.panel)
+
+	barX := left + w.layout.panelPadding
+	barHeight := height - 2 * w.layout.panelPadding
+	whiteHeight := int32(float32(barHeight) * evalFraction(analysis))
+	rl.DrawRectangle(barX, w.layout.panelPadding, w.layout.evalBarWidth, barHeight - whiteHeight, colorEvalBlack)
+	rl.DrawRectangle(barX, w.layout.panelPadding + barHeight - whiteHeight, w.layout.evalBarWidth, whiteHeight, colorEvalWhite)
+
+	w.drawAnalysis(analysis)
+	w.drawMoveList(game)
+}
+
+func (w *Window) drawAnalysis(analysis chess2.Analysis) {
+	y := w.layout.panelPadding
+	drawLine := func(text string, color rl.Color) {
+		if y + w.layout.panelFontSize > w.layout.analysisHeight {
+			return
+		}
+		rl.DrawText(text, w.layout.panelTextX, y, w.layout.panelFontSize, color)
+		y += w.layout.panelLineHeight
+	}
+
+	if analysis.Depth == 0 {
+		drawLine("waiting for the AI", colorPanelDim)
+		return
+	}
+
+	drawLine(formatEval(analysis), w.palette.panelText)
+	drawLine(fmt.Sprintf("depth %d", analysis.Depth), colorPanelDim)
+	drawLine(fmt.Sprintf("nodes %d", analysis.Nodes), colorPanelDim)
+	for _, line := range wrapText(pvTokens(analysis), w.layout.panelTextWidth, w.layout.panelFontSize) {
+		drawLine(line, w.palette.panelText)
+	}
+}
@@ -0,0 +1,54 @@
+package iosystem
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+var colorPromotionShade rl.Color = rl.GetColor(0x00000080)
+
+var promotionPieces = []chess2.Piece{
+	chess2.PieceWhiteQueen,
+	chess2.PieceWhiteKnight,
+	chess2.PieceWhiteRook,
+	chess2.PieceWhiteBishop,
+}
+
+// promotionSquare is where the i-th piece of the chooser is shown: on the
+// target file, going from the last rank towards the centre
+func (w *Window) promotionSquare(i int) (int, int) {
+	if w.promotionMove.Y2 == 0 {
+		return w.promotionMove.X2, i
+	}
+	return w.promotionMove.X2, chess2.BoardSize - 1 - i
+}
+
+func promotionPiece(board *chess2.Board, i int) chess2.Piece {
+	return promotionPieces[i] + chess2.Piece(1 - board.Turn)
+}
+
+// promotionAt returns the promotion piece at the square or PieceNone
+func (w *Window) promotionAt(board *chess2.Board, x, y int) chess2.Piece {
+	for i := range promotionPieces {
+		if px, py := w.promotionSquare(i); px == x && py == y {
+			return promotionPiece(board, i)
+		}
+	}
+	return chess2.PieceNone
+}
+
+func (w *Window) drawPromotion(board *chess2.Board, hoverX, hoverY int) {
+	rl.DrawRectangle(0, 0, int32(w.layout.boardSize), int32(w.layout.boardSize), colorPromotionShade)
+	for i := range promotionPieces {
+		x, y := w.promotionSquare(i)
+		color := w.palette.whiteSquare
+		if x == hoverX && y == hoverY {
+			color = w.palette.selected
+		}
+
+		renderX := int32(x * w.layout.totalCellSize)
+		renderY := int32(y * w.layout.totalCellSize)
+		rl.DrawRectangle(renderX, renderY, int32(w.layout.totalCellSize), int32(w.layout.totalCellSize), color)
+		rl.DrawTexture(w.resources.pieceSprites[promotionPiece(board, i)].texture, renderX, renderY, rl.White)
+	}
+}
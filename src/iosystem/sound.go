@@ -0,0 +1,67 @@
+package iosystem
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	chess2 "github.com/girvel/chess2/src"
+)
+
+const volumeStep float32 = 0.1
+
+func (w *Window) initSound() {
+	rl.InitAudioDevice()
+	w.applyVolume()
+	w.resources.loadSounds()
+}
+
+func (w *Window) deinitSound() {
+	w.resources.unloadSounds()
+	rl.CloseAudioDevice()
+}
+
+func (w *Window) applyVolume() {
+	if w.muted {
+		rl.SetMasterVolume(0)
+	} else {
+		rl.SetMasterVolume(w.volume)
+	}
+}
+
+func (w *Window) readSoundInput() {
+	switch {
+	case rl.IsKeyPressed(rl.KeyM):
+		w.muted = !w.muted
+	case rl.IsKeyPressed(rl.KeyMinus):
+		w.volume = max(0, w.volume - volumeStep)
+	case rl.IsKeyPressed(rl.KeyEqual):
+		w.volume = min(1, w.volume + volumeStep)
+	default:
+		return
+	}
+	w.applyVolume()
+}
+
+// forMove picks the sound of the move that led to the position after
+func (s *sounds) forMove(game *chess2.Game, ply int) rl.Sound {
+	before := &game.Positions[ply - 1]
+	after := &game.Positions[ply]
+	m := game.Moves[ply - 1]
+
+	switch {
+	case ply == len(game.Positions) - 1 && game.Result == chess2.ResultWhiteWins: return s.win
+	case ply == len(game.Positions) - 1 && game.Result == chess2.ResultBlackWins: return s.loss
+	case after.InCheck(): return s.check
+	case before.IsPromotion(m): return s.promotion
+	case before.WillBeCastle(m): return s.castle
+	case m.IsCapture(before): return s.capture
+	default: return s.move
+	}
+}
+
+// updateSound plays a sound when the shown position advances by one move
+func (w *Window) updateSound(game *chess2.Game) {
+	ply := w.currentPly(game)
+	if ply == w.soundedPly + 1 {
+		rl.PlaySound(w.resources.sounds.forMove(game, ply))
+	}
+	w.soundedPly = ply
+}
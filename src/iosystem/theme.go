@@ -0,0 +1,67 @@
+package iosystem
+
+import (
+	"io/fs"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/girvel/chess2/src/theme"
+)
+
+// palette holds the current theme's colours in raylib's form
+type palette struct {
+	whiteSquare, blackSquare rl.Color
+	selected rl.Color
+	lastMoveLight, lastMoveDark rl.Color
+	hint, check rl.Color
+	panel, panelText rl.Color
+}
+
+func newPalette(t theme.Theme) palette {
+	return palette{
+		whiteSquare: rl.Color(t.WhiteSquare),
+		blackSquare: rl.Color(t.BlackSquare),
+		selected: rl.Color(t.Selected),
+		lastMoveLight: rl.Color(t.LastMoveLight),
+		lastMoveDark: rl.Color(t.LastMoveDark),
+		hint: rl.Color(t.Hint),
+		check: rl.Color(t.Check),
+		panel: rl.Color(t.Panel),
+		panelText: rl.Color(t.PanelText),
+	}
+}
+
+// loadThemes reads every theme in theme.Dir, fields missing from a file keep
+// their default values
+func loadThemes(assets fs.FS) []theme.Theme {
+	var result []theme.Theme
+	for _, name := range theme.Names(assets) {
+		t, err := theme.Load(assets, name)
+		if err != nil {
+			rl.TraceLog(rl.LogWarning, "%s", err)
+			continue
+		}
+		result = append(result, t)
+	}
+
+	if !slices.ContainsFunc(result, func(t theme.Theme) bool { return t.Name == theme.Default.Name }) {
+		result = append([]theme.Theme{theme.Default}, result...)
+	}
+	return result
+}
+
+func (w *Window) initThemes(name string) {
+	w.themes = loadThemes(w.resources.assets)
+	w.themeIndex = max(0, slices.IndexFunc(w.themes, func(t theme.Theme) bool { return t.Name == name }))
+	w.applyTheme(w.themes[w.themeIndex])
+}
+
+func (w *Window) cycleTheme() {
+	w.themeIndex = (w.themeIndex + 1) % len(w.themes)
+	w.applyTheme(w.themes[w.themeIndex])
+}
+
+func (w *Window) applyTheme(t theme.Theme) {
+	w.palette = newPalette(t)
+	w.resources.loadSprites(t)
+}
@@ -0,0 +1,229 @@
+package chess2
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+type MatchOptions struct {
+	Games int
+	Concurrency int
+	Limits SearchLimits
+	Openings []Board
+	// MaxPlies adjudicates longer games as draws, zero for no limit
+	MaxPlies int
+	// SPRT stops the match as soon as it accepts one of the hypotheses
+	SPRT *SPRT
+}
+
+type SPRT struct {
+	Elo0, Elo1 float64
+	Alpha, Beta float64
+}
+
+// MatchStats are counted from the first engine's point of view
+type MatchStats struct {
+	Wins, Draws, Losses int
+}
+
+func (s MatchStats) Games() int {
+	return s.Wins + s.Draws + s.Losses
+}
+
+func (s MatchStats) score() (mean, variance float64) {
+	n := float64(s.Games())
+	mean = (float64(s.Wins) + float64(s.Draws) / 2) / n
+	variance = (
+		float64(s.Wins) * math.Pow(1 - mean, 2) +
+		float64(s.Draws) * math.Pow(0.5 - mean, 2) +
+		float64(s.Losses) * math.Pow(mean, 2)) / n
+	return mean, variance
+}
+
+func eloFromScore(score float64) float64 {
+	score = min(max(score, 1e-6), 1 - 1e-6)
+	return -400 * math.Log10(1 / score - 1)
+}
+
+func scoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo / 400))
+}
+
+// Elo estimates the Elo difference with a 95% confidence margin
+func (s MatchStats) Elo() (diff, margin float64) {
+	if s.Games() == 0 {
+		return 0, math.Inf(1)
+	}
+
+	mean, variance := s.score()
+	deviation := 1.96 * math.Sqrt(variance / float64(s.Games()))
+	low := eloFromScore(mean - deviation)
+	high := eloFromScore(mean + deviation)
+	return eloFromScore(mean), (high - low) / 2
+}
+
+// LLR is the log-likelihood ratio of the SPRT, using the normal approximation
+// of the trinomial model
+func (s MatchStats) LLR(sprt SPRT) float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+
+	mean, variance := s.score()
+	if variance == 0 {
+		return 0
+	}
+	s0 := scoreFromElo(sprt.Elo0)
+	s1 := scoreFromElo(sprt.Elo1)
+	return float64(s.Games()) * (s1 - s0) * (2 * mean - s0 - s1) / (2 * variance)
+}
+
+func (sprt SPRT) Bounds() (lower, upper float64) {
+	return math.Log(sprt.Beta / (1 - sprt.Alpha)), math.Log((1 - sprt.Beta) / sprt.Alpha)
+}
+
+// Verdict is "H1" when the first engine is stronger by Elo1, "H0" when it is
+// not stronger by Elo0 and empty while the test continues
+func (s MatchStats) Verdict(sprt SPRT) string {
+	llr := s.LLR(sprt)
+	lower, upper := sprt.Bounds()
+	switch {
+	case llr >= upper: return "H1"
+	case llr <= lower: return "H0"
+	default: return ""
+	}
+}
+
+// PlayGame lets the engines play from start until the game ends
+func PlayGame(white, black Engine, start Board, limits SearchLimits, maxPlies int) (*Game, error) {
+	game := NewGame(start)
+	game.Tags["White"] = white.Name()
+	game.Tags["Black"] = black.Name()
+
+	for game.Result == ResultNone {
+		if maxPlies > 0 && len(game.Moves) >= maxPlies {
+			game.Adjudicate(ResultDraw, "move limit")
+			break
+		}
+
+		board := game.Board()
+		engine := white
+		if board.Turn == SideBlack {
+			engine = black
+		}
+
+		m, err := engine.BestMove(game, limits)
+		switch {
+		case errors.Is(err, ErrNoMove):
+			game.Adjudicate(ResultDraw, "stalemate")
+		case errors.Is(err, ErrIllegalMove):
+			game.Resign(board.Turn, err.Error())
+		case err != nil:
+			return nil, fmt.Errorf("%s: %w", engine.Name(), err)
+		case !board.IsMoveLegal(m):
+			game.Resign(board.Turn, fmt.Sprintf("illegal move %s", m))
+		default:
+			game.Play(m)
+		}
+	}
+
+	return game, nil
+}
+
+// PlayMatch plays pairs of games from each opening with swapped colours,
+// calling onGame after each one; newEngine creates the first engine when
+// given 0 and the second when given 1
+func PlayMatch(
+	newEngine func(int) (Engine, error), options MatchOptions,
+	onGame func(round int, game *Game, stats MatchStats),
+) (MatchStats, error) {
+	openings := options.Openings
+	if len(openings) == 0 {
+		openings = []Board{*EmptyBoard()}
+	}
+
+	var mutex sync.Mutex
+	var stats MatchStats
+	var firstErr error
+	next := 0
+	done := false
+
+	claim := func() (int, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if done || firstErr != nil || next >= options.Games {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+
+	worker := func() error {
+		var engines [2]Engine
+		for i := range engines {
+			engine, err := newEngine(i)
+			if err != nil {
+				return err
+			}
+			defer engine.Close()
+			engines[i] = engine
+		}
+
+		for {
+			round, ok := claim()
+			if !ok {
+				return nil
+			}
+
+			for _, engine := range engines {
+				if err := engine.NewGame(); err != nil {
+					return err
+				}
+			}
+
+			firstIsWhite := round % 2 == 0
+			white, black := engines[0], engines[1]
+			if !firstIsWhite {
+				white, black = black, white
+			}
+
+			game, err := PlayGame(white, black, openings[round / 2 % len(openings)], options.Limits, options.MaxPlies)
+			if err != nil {
+				return err
+			}
+			game.Tags["Round"] = fmt.Sprint(round + 1)
+
+			mutex.Lock()
+			switch {
+			case game.Result == ResultDraw: stats.Draws++
+			case (game.Result == ResultWhiteWins) == firstIsWhite: stats.Wins++
+			default: stats.Losses++
+			}
+			if options.SPRT != nil && stats.Verdict(*options.SPRT) != "" {
+				done = true
+			}
+			if onGame != nil {
+				onGame(round, game, stats)
+			}
+			mutex.Unlock()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for range max(options.Concurrency, 1) {
+		wg.Go(func() {
+			if err := worker(); err != nil {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	return stats, firstErr
+}
@@ -0,0 +1,205 @@
+package chess2
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IsCheckmate reports whether the side to move cannot avoid losing its king
+func (b *Board) IsCheckmate() bool {
+	if !b.InCheck() {
+		return false
+	}
+
+	for _, m := range b.AllMoves() {
+		next := b.Apply(m)
+		if next.Winner != SideNone {
+			return false
+		}
+		next.Turn = 1 - next.Turn
+		if !next.InCheck() {
+			return false
+		}
+	}
+	return true
+}
+
+// exposesKing reports whether the opponent could capture the mover's king
+// after m, like after a move by a pinned piece
+func (b *Board) exposesKing(m Move) bool {
+	next := b.Apply(m)
+	if next.Winner != SideNone {
+		return false
+	}
+	next.Turn = 1 - next.Turn
+	return next.InCheck()
+}
+
+func (b *Board) SAN(m Move) string {
+	source := *b.At(m.X1, m.Y1)
+	var result string
+
+	switch {
+	case b.WillBeCastle(m) && m.X2 > m.X1:
+		result = "O-O"
+
+	case b.WillBeCastle(m):
+		result = "O-O-O"
+
+	default:
+		isPawn := source == PieceWhitePawn || source == PieceBlackPawn
+		result = source.Letter()
+
+		if isPawn {
+			if m.IsCapture(b) {
+				result += string(rune('a' + m.X1))
+			}
+		} else {
+			sameFile, sameRank, ambiguous := false, false, false
+			for _, other := range b.AllMoves() {
+				if *b.At(other.X1, other.Y1) != source ||
+					other.X2 != m.X2 || other.Y2 != m.Y2 ||
+					other.X1 == m.X1 && other.Y1 == m.Y1 ||
+					b.exposesKing(other) {
+					continue
+				}
+				ambiguous = true
+				sameFile = sameFile || other.X1 == m.X1
+				sameRank = sameRank || other.Y1 == m.Y1
+			}
+
+			switch {
+			case !ambiguous:
+			case !sameFile: result += string(rune('a' + m.X1))
+			case !sameRank: result += fmt.Sprint(BoardSize - m.Y1)
+			default: result += squareName(m.X1, m.Y1)
+			}
+		}
+
+		if m.IsCapture(b) {
+			result += "x"
+		}
+		result += squareName(m.X2, m.Y2)
+
+		if b.IsPromotion(m) {
+			promotion := m.Promotion
+			if promotion == PieceNone {
+				promotion = PieceWhiteQueen
+			}
+			result += "=" + promotion.Letter()
+		}
+	}
+
+	next := b.Apply(m)
+	switch {
+	case next.Winner != SideNone: result += "#"
+	case next.IsCheckmate(): result += "#"
+	case next.InCheck(): result += "+"
+	}
+	return result
+}
+
+var sanPattern = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?x?([a-h][1-8])(?:=?([NBRQ]))?$`)
+
+func (b *Board) ParseSAN(san string) (Move, error) {
+	clean := strings.TrimRight(strings.TrimSpace(san), "+#!?")
+	clean = strings.ReplaceAll(clean, "0", "O")
+
+	isCastle := clean == "O-O" || clean == "O-O-O"
+	groups := sanPattern.FindStringSubmatch(clean)
+	if !isCastle && groups == nil {
+		return Move{}, fmt.Errorf("%q is not a move in SAN", san)
+	}
+
+	var candidates []Move
+	for _, m := range b.AllMoves() {
+		if isCastle {
+			if b.WillBeCastle(m) && (m.X2 > m.X1) == (clean == "O-O") {
+				candidates = append(candidates, m)
+			}
+			continue
+		}
+
+		source := *b.At(m.X1, m.Y1)
+		x2, y2, _ := parseSquare(groups[4])
+		if source.Letter() != groups[1] ||
+			m.X2 != x2 || m.Y2 != y2 ||
+			groups[2] != "" && m.X1 != int(groups[2][0] - 'a') ||
+			groups[3] != "" && m.Y1 != BoardSize - int(groups[3][0] - '0') ||
+			b.WillBeCastle(m) {
+			continue
+		}
+
+		if b.IsPromotion(m) {
+			promotion := groups[5]
+			if promotion == "" {
+				promotion = "Q"
+			}
+			if m.Promotion.Letter() != promotion {
+				continue
+			}
+		}
+
+		candidates = append(candidates, m)
+	}
+
+	// SAN only disambiguates between moves that don't expose the king
+	if len(candidates) > 1 {
+		if safe := slices.DeleteFunc(slices.Clone(candidates), b.exposesKing); len(safe) > 0 {
+			candidates = safe
+		}
+	}
+
+	switch len(candidates) {
+	case 0: return Move{}, fmt.Errorf("%q is not a legal move", san)
+	case 1: return candidates[0], nil
+	default: return Move{}, fmt.Errorf("%q is ambiguous", san)
+	}
+}
+
+var coordinatePattern = regexp.MustCompile(`^([a-h][1-8])-?([a-h][1-8])([nbrqNBRQ])?$`)
+
+// ParseMove reads a move in coordinate notation, like e2e4, e2-e4 or e7e8q
+func (b *Board) ParseMove(s string) (Move, error) {
+	groups := coordinatePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if groups == nil {
+		return Move{}, fmt.Errorf("%q is not a move in coordinate notation", s)
+	}
+
+	x1, y1, _ := parseSquare(groups[1])
+	x2, y2, _ := parseSquare(groups[2])
+	result := NewMove(x1, y1, x2, y2)
+	if groups[3] != "" {
+		for _, piece := range []Piece{PieceWhiteKnight, PieceWhiteBishop, PieceWhiteRook, PieceWhiteQueen} {
+			if piece.Letter() == strings.ToUpper(groups[3]) {
+				result.Promotion = piece + Piece(1 - b.Turn)
+			}
+		}
+	} else if b.IsPromotion(result) {
+		result.Promotion = PieceWhiteQueen + Piece(1 - b.Turn)
+	}
+
+	if !b.IsMoveLegal(result) {
+		return Move{}, fmt.Errorf("%s is not a legal move", s)
+	}
+	return result, nil
+}
+
+// ParseAnyMove accepts both coordinate notation and SAN
+func (b *Board) ParseAnyMove(s string) (Move, error) {
+	if coordinatePattern.MatchString(strings.TrimSpace(s)) {
+		return b.ParseMove(s)
+	}
+	return b.ParseSAN(s)
+}
+
+// UCI formats the move in the coordinate notation used by the UCI protocol
+func (m Move) UCI() string {
+	result := squareName(m.X1, m.Y1) + squareName(m.X2, m.Y2)
+	if m.Promotion != PieceNone {
+		result += strings.ToLower(m.Promotion.Letter())
+	}
+	return result
+}
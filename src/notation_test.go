@@ -0,0 +1,63 @@
+package chess2
+
+import "testing"
+
+func TestSAN(t *testing.T) {
+	tests := []struct {
+		fen string
+		move string
+		san string
+	}{
+		{StartFEN, "e2e4", "e4"},
+		{StartFEN, "g1f3", "Nf3"},
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", "f3e5", "Nxe5"},
+		{"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", "O-O"},
+		{"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", "O-O-O"},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8n", "a8=N"},
+		{"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "e5d6", "exd6"},
+		{"4k3/8/8/8/8/5N2/8/RN2K2R w - - 0 1", "b1d2", "Nbd2"},
+		{"6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", "a1a8", "Ra8#"},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", "Ra8+"},
+	}
+
+	for _, test := range tests {
+		board, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := board.ParseMove(test.move)
+		if err != nil {
+			t.Errorf("%s: %s", test.fen, err)
+			continue
+		}
+		if san := board.SAN(m); san != test.san {
+			t.Errorf("%s: %s is written as %s, expected %s", test.fen, test.move, san, test.san)
+		}
+
+		parsed, err := board.ParseSAN(test.san)
+		if err != nil {
+			t.Errorf("%s: %s", test.fen, err)
+		} else if parsed != m {
+			t.Errorf("%s: %s is read as %s, expected %s", test.fen, test.san, parsed, m)
+		}
+	}
+}
+
+func TestParseSANIgnoresPinnedPieces(t *testing.T) {
+	board, err := ParseFEN("4k3/8/8/8/1b6/2N5/8/4K1N1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := board.ParseSAN("Ne2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != NewMove(6, 7, 4, 6) {
+		t.Errorf("Ne2 is read as %s, expected g1-e2", m)
+	}
+	if san := board.SAN(m); san != "Ne2" {
+		t.Errorf("g1-e2 is written as %s, expected Ne2", san)
+	}
+}
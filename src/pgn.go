@@ -0,0 +1,256 @@
+package chess2
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var pgnRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+func (g *Game) WritePGN(w io.Writer) error {
+	tags := make(map[string]string)
+	for _, tag := range pgnRoster {
+		tags[tag] = "?"
+	}
+	for tag, value := range g.Tags {
+		tags[tag] = value
+	}
+	tags["Result"] = string(g.Result)
+	if g.Termination != "" {
+		tags["Termination"] = g.Termination
+	}
+	if start := g.Start().FEN(); start != StartFEN {
+		tags["SetUp"] = "1"
+		tags["FEN"] = start
+	}
+
+	var extra []string
+	for tag := range tags {
+		if !slices.Contains(pgnRoster, tag) {
+			extra = append(extra, tag)
+		}
+	}
+	slices.Sort(extra)
+
+	var result strings.Builder
+	for _, tag := range append(slices.Clone(pgnRoster), extra...) {
+		value := strings.ReplaceAll(tags[tag], `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		fmt.Fprintf(&result, "[%s \"%s\"]\n", tag, value)
+	}
+	result.WriteString("\n")
+
+	var tokens []string
+	if comment, ok := g.Comments[0]; ok {
+		tokens = append(tokens, "{" + comment + "}")
+	}
+	for i := range g.Moves {
+		// a black move after a comment repeats its number, like "1... e5"
+		if _, commented := g.Comments[i]; i == 0 || commented || g.Positions[i].Turn == SideWhite {
+			tokens = append(tokens, g.MoveNumber(i))
+		}
+		tokens = append(tokens, g.SAN(i))
+		if comment, ok := g.Comments[i + 1]; ok {
+			tokens = append(tokens, "{" + comment + "}")
+		}
+	}
+	tokens = append(tokens, string(g.Result))
+
+	lineLength := 0
+	for i, token := range tokens {
+		if i > 0 && lineLength + 1 + len(token) > 79 {
+			result.WriteString("\n")
+			lineLength = 0
+		} else if i > 0 {
+			result.WriteString(" ")
+			lineLength++
+		}
+		result.WriteString(token)
+		lineLength += len(token)
+	}
+	result.WriteString("\n\n")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+var moveNumberPattern = regexp.MustCompile(`^\d+\.*`)
+
+// ReadPGN reads every game of a PGN file; variations, NAGs and escaped lines
+// are skipped, the games may start from a FEN tag
+func ReadPGN(r io.Reader) ([]*Game, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+
+	var result []*Game
+	var game *Game
+	tags := make(map[string]string)
+
+	// startGame creates the game once its movetext begins, after all the tags
+	startGame := func() error {
+		if game != nil {
+			return nil
+		}
+
+		start := StartFEN
+		if fen, ok := tags["FEN"]; ok {
+			start = fen
+		}
+		board, err := ParseFEN(start)
+		if err != nil {
+			return err
+		}
+
+		game = NewGame(*board)
+		for tag, value := range tags {
+			switch tag {
+			case "Result", "Termination", "SetUp", "FEN":
+			default: game.Tags[tag] = value
+			}
+		}
+		return nil
+	}
+
+	// endGame keeps a result detected from the moves unless the PGN disagrees
+	endGame := func(gameResult GameResult) {
+		if gameResult != ResultNone && gameResult != game.Result {
+			game.Adjudicate(gameResult, tags["Termination"])
+		}
+		result = append(result, game)
+		game = nil
+		tags = make(map[string]string)
+	}
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+
+		case c == '%' && (i == 0 || s[i - 1] == '\n'), c == ';':
+			i = lineEnd(s, i)
+
+		case c == '[':
+			end := tagEnd(s, i)
+			if end < 0 {
+				return nil, fmt.Errorf("PGN has an unterminated tag at %d", i)
+			}
+
+			tag, value, err := parseTag(s[i + 1:end])
+			if err != nil {
+				return nil, err
+			}
+			tags[tag] = value
+			i = end + 1
+
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("PGN has an unterminated comment at %d", i)
+			}
+			if err := startGame(); err != nil {
+				return nil, err
+			}
+
+			ply := len(game.Positions) - 1
+			comment := strings.TrimSpace(s[i + 1:i + end])
+			if previous, ok := game.Comments[ply]; ok {
+				comment = previous + " " + comment
+			}
+			game.Comments[ply] = comment
+			i += end + 1
+
+		case c == '(':
+			depth := 0
+			for ; i < len(s); i++ {
+				switch s[i] {
+				case '(': depth++
+				case ')': depth--
+				case '{': i += max(0, strings.IndexByte(s[i:], '}'))
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			i++
+
+		default:
+			end := i + 1
+			for end < len(s) && !strings.ContainsRune(" \t\r\n{}()[];", rune(s[end])) {
+				end++
+			}
+			token := s[i:end]
+			i = end
+
+			if c == '$' {
+				continue
+			}
+
+			if err := startGame(); err != nil {
+				return nil, err
+			}
+
+			switch gameResult := GameResult(token); gameResult {
+			case ResultWhiteWins, ResultBlackWins, ResultDraw, ResultNone:
+				endGame(gameResult)
+				continue
+			}
+
+			token = moveNumberPattern.ReplaceAllString(token, "")
+			if token == "" {
+				continue
+			}
+
+			m, err := game.Board().ParseSAN(token)
+			if err != nil {
+				return nil, fmt.Errorf("PGN move %s %s: %w", game.MoveNumber(len(game.Moves)), token, err)
+			}
+			game.Play(m)
+		}
+	}
+
+	if game != nil {
+		endGame(ResultNone)
+	}
+	return result, nil
+}
+
+func lineEnd(s string, i int) int {
+	if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+		return i + end + 1
+	}
+	return len(s)
+}
+
+// tagEnd finds the "]" closing the tag that starts at i, skipping the ones
+// inside of its quoted value
+func tagEnd(s string, i int) int {
+	quoted := false
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\' && quoted: j++
+		case s[j] == '"': quoted = !quoted
+		case s[j] == ']' && !quoted: return j
+		}
+	}
+	return -1
+}
+
+// parseTag reads the inside of a tag pair like `Event "Casual game"`
+func parseTag(s string) (string, string, error) {
+	name, quoted, ok := strings.Cut(strings.TrimSpace(s), " ")
+	quoted = strings.TrimSpace(quoted)
+	if !ok || len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted) - 1] != '"' {
+		return "", "", fmt.Errorf("PGN has an invalid tag [%s]", s)
+	}
+
+	value := quoted[1:len(quoted) - 1]
+	value = strings.ReplaceAll(value, `\"`, `"`)
+	value = strings.ReplaceAll(value, `\\`, `\`)
+	return name, value, nil
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+const glyphWidth = 5
+const glyphHeight = 7
+
+// glyphs is a small bitmap font with the characters of coordinates, move
+// numbers and SAN; other characters are drawn as spaces
+var glyphs = map[rune][glyphHeight]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'a': {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "####."},
+	'c': {".....", ".....", ".###.", "#....", "#....", "#...#", ".###."},
+	'd': {"....#", "....#", ".####", "#...#", "#...#", "#...#", ".####"},
+	'e': {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f': {"..##.", ".#..#", ".#...", "###..", ".#...", ".#...", ".#..."},
+	'g': {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h': {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'x': {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'=': {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'/': {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'*': {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+}
+
+// textWidth is the width of text drawn with the pixel size dot
+func textWidth(text string, dot int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n * (glyphWidth + 1) - 1) * dot
+}
+
+func textHeight(dot int) int {
+	return glyphHeight * dot
+}
+
+// drawText draws text with its top-left corner at (x, y), every font pixel
+// being a dot by dot square
+func drawText(img *image.NRGBA, x, y, dot int, text string, c color.NRGBA) {
+	for _, r := range text {
+		for row, line := range glyphs[r] {
+			for column, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				for py := range dot {
+					for px := range dot {
+						img.SetNRGBA(x + column * dot + px, y + row * dot + py, c)
+					}
+				}
+			}
+		}
+		x += (glyphWidth + 1) * dot
+	}
+}
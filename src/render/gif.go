@@ -0,0 +1,98 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	chess2 "github.com/girvel/chess2/src"
+)
+
+type GIFOptions struct {
+	// Delay is how long every position is shown, FinalDelay is for the last one
+	Delay time.Duration
+	FinalDelay time.Duration
+	// Caption adds a line with the move in SAN below the board
+	Caption bool
+}
+
+func DefaultGIFOptions() GIFOptions {
+	return GIFOptions{
+		Delay: time.Second,
+		FinalDelay: 3 * time.Second,
+		Caption: true,
+	}
+}
+
+// caption describes the move that led to the i-th position, with the result
+// after the last one
+func caption(game *chess2.Game, i int) string {
+	var result string
+	if i > 0 {
+		result = game.MoveNumber(i - 1) + " " + game.SAN(i - 1)
+	}
+	if i == len(game.Positions) - 1 && game.Result != chess2.ResultNone {
+		result += " " + string(game.Result)
+	}
+	return result
+}
+
+// WriteGIF writes an animated GIF with a frame for every position of the game
+func (r *Renderer) WriteGIF(w io.Writer, game *chess2.Game, options GIFOptions) error {
+	dot := max(1, r.options.CellSize / 16)
+	padding := max(1, r.options.CellSize / 8)
+	captionHeight := 0
+	if options.Caption {
+		captionHeight = textHeight(dot) + 2 * padding
+	}
+
+	var result gif.GIF
+	for i := range game.Positions {
+		frame := image.NewNRGBA(image.Rect(0, 0, r.Size(), r.Size() + captionHeight))
+		draw.Draw(frame, frame.Bounds(), r.Render(&game.Positions[i]), image.Point{}, draw.Src)
+		if options.Caption {
+			strip := image.Rect(0, r.Size(), r.Size(), r.Size() + captionHeight)
+			draw.Draw(frame, strip, &image.Uniform{color.NRGBA(r.theme.Panel)}, image.Point{}, draw.Src)
+			drawText(frame, padding, r.Size() + padding, dot, caption(game, i), color.NRGBA(r.theme.PanelText))
+		}
+
+		delay := options.Delay
+		if i == len(game.Positions) - 1 {
+			delay = max(delay, options.FinalDelay)
+		}
+		result.Image = append(result.Image, paletted(frame))
+		result.Delay = append(result.Delay, int(delay / (10 * time.Millisecond)))
+	}
+	return gif.EncodeAll(w, &result)
+}
+
+// paletted keeps the exact colours of a frame when there are at most 256 of
+// them, which is the case for the pixel art sprites without arrows
+func paletted(img *image.NRGBA) *image.Paletted {
+	var colors color.Palette
+	indices := make(map[color.NRGBA]uint8)
+	pixels := make([]uint8, 0, len(img.Pix) / 4)
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.NRGBA{img.Pix[i], img.Pix[i + 1], img.Pix[i + 2], img.Pix[i + 3]}
+		index, ok := indices[c]
+		if !ok {
+			if len(colors) == 256 {
+				result := image.NewPaletted(img.Bounds(), palette.Plan9)
+				draw.Draw(result, result.Bounds(), img, image.Point{}, draw.Src)
+				return result
+			}
+			index = uint8(len(colors))
+			indices[c] = index
+			colors = append(colors, c)
+		}
+		pixels = append(pixels, index)
+	}
+
+	result := image.NewPaletted(img.Bounds(), colors)
+	result.Pix = pixels
+	return result
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+
+	"github.com/girvel/chess2/src/theme"
+)
+
+func loadImage(assets fs.FS, name string) (*image.NRGBA, error) {
+	data, err := theme.ReadAsset(assets, name)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	result := image.NewNRGBA(decoded.Bounds().Sub(decoded.Bounds().Min))
+	draw.Draw(result, result.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	return result, nil
+}
+
+// replaceColor recolours the pixels of exactly one colour, which is how
+// black pieces are made from the white sprites
+func replaceColor(img *image.NRGBA, from, to color.NRGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] == from.R && img.Pix[i + 1] == from.G && img.Pix[i + 2] == from.B && img.Pix[i + 3] == from.A {
+			img.Pix[i], img.Pix[i + 1], img.Pix[i + 2], img.Pix[i + 3] = to.R, to.G, to.B, to.A
+		}
+	}
+}
+
+// scale resizes with the nearest neighbour, keeping the pixel art sharp
+func scale(img *image.NRGBA, width, height int) *image.NRGBA {
+	result := image.NewNRGBA(image.Rect(0, 0, width, height))
+	source := img.Bounds()
+	for y := range height {
+		for x := range width {
+			result.SetNRGBA(x, y, img.NRGBAAt(x * source.Dx() / width, y * source.Dy() / height))
+		}
+	}
+	return result
+}
@@ -0,0 +1,299 @@
+// Package render draws positions into images with Go's image packages, so
+// they can be produced without a window or a GPU
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"strings"
+
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/theme"
+)
+
+const spriteSize = 16
+
+type Options struct {
+	// CellSize is the side of a square in pixels
+	CellSize int
+	// Flipped shows the board from black's side
+	Flipped bool
+	Coordinates bool
+	// LastMove highlights the squares of the board's LastMove
+	LastMove bool
+	Arrows []Arrow
+	// Theme is the name of a file in themes/, SpriteDir replaces its sprite
+	// directory when set
+	Theme string
+	SpriteDir string
+	// Assets holds the sprites and themes, the disk is only read for SpriteDir
+	// or when Assets is nil
+	Assets fs.FS
+}
+
+func DefaultOptions() Options {
+	return Options{
+		CellSize: 64,
+		Coordinates: true,
+		LastMove: true,
+		Theme: "default",
+	}
+}
+
+// Arrow is drawn between the centres of two squares, or as a circle around
+// the square when both are the same
+type Arrow struct {
+	X1, Y1, X2, Y2 int
+	Color color.NRGBA
+}
+
+// ParseArrow reads an arrow like "e2e4", optionally prefixed by a colour
+// letter like in PGN [%cal] commands: "Re2e4"
+func ParseArrow(s string) (Arrow, error) {
+	result := Arrow{Color: color.NRGBA(theme.AnnotationColors[0])}
+	if len(s) == 5 {
+		i := strings.IndexByte(theme.AnnotationLetters, s[0])
+		if i < 0 {
+			return result, fmt.Errorf("arrow %q has invalid colour %q, expected one of %s", s, s[0], theme.AnnotationLetters)
+		}
+		result.Color = color.NRGBA(theme.AnnotationColors[i])
+		s = s[1:]
+	}
+
+	var ok1, ok2 bool
+	result.X1, result.Y1, ok1 = parseSquare(s[:min(2, len(s))])
+	result.X2, result.Y2, ok2 = parseSquare(s[min(2, len(s)):])
+	if !ok1 || !ok2 {
+		return result, fmt.Errorf("invalid arrow %q", s)
+	}
+	return result, nil
+}
+
+func parseSquare(s string) (int, int, bool) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, 0, false
+	}
+	return int(s[0] - 'a'), chess2.BoardSize - int(s[1] - '0'), true
+}
+
+// Renderer holds the theme and the sprites scaled to the square size, so
+// many positions can be drawn without loading them again
+type Renderer struct {
+	options Options
+	theme theme.Theme
+	// pieceSprites are indexed by the piece, PieceNone has none
+	pieceSprites []*image.NRGBA
+}
+
+func New(options Options) (*Renderer, error) {
+	t, err := theme.Load(options.Assets, options.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if options.SpriteDir != "" {
+		t.Sprites = options.SpriteDir
+		t.WhiteSprites = ""
+		t.BlackSprites = ""
+	}
+
+	whiteDir := t.WhiteSprites
+	if whiteDir == "" {
+		whiteDir = t.Sprites
+	}
+
+	r := &Renderer{options: options, theme: t, pieceSprites: []*image.NRGBA{nil}}
+	load := func(dir, name string) (*image.NRGBA, error) {
+		img, err := loadImage(theme.SpriteAssets(options.Assets, options.SpriteDir), filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		return scale(
+			img,
+			img.Bounds().Dx() * options.CellSize / spriteSize,
+			img.Bounds().Dy() * options.CellSize / spriteSize,
+		), nil
+	}
+
+	for _, name := range []string{"pawn.png", "knight.png", "bishop.png", "rook.png", "queen.png", "king.png"} {
+		white, err := load(whiteDir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var black *image.NRGBA
+		if t.BlackSprites != "" {
+			black, err = load(t.BlackSprites, name)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			black = image.NewNRGBA(white.Bounds())
+			copy(black.Pix, white.Pix)
+			replaceColor(black, color.NRGBA(t.WhitePiece), color.NRGBA(t.BlackPiece))
+		}
+		r.pieceSprites = append(r.pieceSprites, white, black)
+	}
+	return r, nil
+}
+
+// Size is the side of the rendered image in pixels
+func (r *Renderer) Size() int {
+	return chess2.BoardSize * r.options.CellSize
+}
+
+// screenSquare converts board coordinates into the square's place in the
+// image, which differs when the board is flipped
+func (r *Renderer) screenSquare(x, y int) (int, int) {
+	if r.options.Flipped {
+		return chess2.BoardSize - 1 - x, chess2.BoardSize - 1 - y
+	}
+	return x, y
+}
+
+func (r *Renderer) Render(board *chess2.Board) *image.NRGBA {
+	cell := r.options.CellSize
+	result := image.NewNRGBA(image.Rect(0, 0, r.Size(), r.Size()))
+
+	for x := range chess2.BoardSize {
+		for y := range chess2.BoardSize {
+			lastMove := r.options.LastMove && board.LastMove != nil && (
+				x == board.LastMove.X1 && y == board.LastMove.Y1 ||
+				x == board.LastMove.X2 && y == board.LastMove.Y2)
+
+			var squareColor theme.Color
+			switch {
+			case (x + y) % 2 == 0 && lastMove: squareColor = r.theme.LastMoveLight
+			case (x + y) % 2 == 0: squareColor = r.theme.WhiteSquare
+			case lastMove: squareColor = r.theme.LastMoveDark
+			default: squareColor = r.theme.BlackSquare
+			}
+
+			screenX, screenY := r.screenSquare(x, y)
+			square := image.Rect(screenX * cell, screenY * cell, (screenX + 1) * cell, (screenY + 1) * cell)
+			draw.Draw(result, square, &image.Uniform{color.NRGBA(squareColor)}, image.Point{}, draw.Src)
+			if r.options.Coordinates {
+				r.drawCoordinates(result, x, y)
+			}
+
+			if piece := *board.At(x, y); piece != chess2.PieceNone {
+				sprite := r.pieceSprites[piece]
+				draw.Draw(result, square, sprite, sprite.Bounds().Min, draw.Over)
+			}
+		}
+	}
+
+	for _, a := range r.options.Arrows {
+		r.drawArrow(result, a)
+	}
+	return result
+}
+
+// drawCoordinates labels the files along the bottom edge and the ranks along
+// the left one, in the colour of the opposite squares
+func (r *Renderer) drawCoordinates(img *image.NRGBA, x, y int) {
+	textColor := color.NRGBA(r.theme.WhiteSquare)
+	if (x + y) % 2 == 0 {
+		textColor = color.NRGBA(r.theme.BlackSquare)
+	}
+
+	cell := r.options.CellSize
+	dot := max(1, cell / 32)
+	padding := max(1, cell / 24)
+	screenX, screenY := r.screenSquare(x, y)
+	if screenX == 0 {
+		drawText(img, padding, screenY * cell + padding, dot, fmt.Sprint(chess2.BoardSize - y), textColor)
+	}
+	if screenY == chess2.BoardSize - 1 {
+		file := string(rune('a' + x))
+		drawText(
+			img,
+			(screenX + 1) * cell - textWidth(file, dot) - padding,
+			(screenY + 1) * cell - textHeight(dot) - padding,
+			dot, file, textColor,
+		)
+	}
+}
+
+type point struct {
+	x, y float64
+}
+
+func (r *Renderer) squareCenter(x, y int) point {
+	screenX, screenY := r.screenSquare(x, y)
+	cell := float64(r.options.CellSize)
+	return point{(float64(screenX) + 0.5) * cell, (float64(screenY) + 0.5) * cell}
+}
+
+// drawArrow keeps the arrow's shape in a mask first, so the translucent
+// colour is applied once where the shaft and the head overlap
+func (r *Renderer) drawArrow(img *image.NRGBA, a Arrow) {
+	cell := float64(r.options.CellSize)
+	from := r.squareCenter(a.X1, a.Y1)
+	to := r.squareCenter(a.X2, a.Y2)
+	mask := image.NewAlpha(img.Bounds())
+
+	if a.X1 == a.X2 && a.Y1 == a.Y2 {
+		fillRing(mask, from, cell / 2 - cell / 12, cell / 2)
+	} else {
+		width, headLength, headWidth := cell / 6, cell * 3 / 8, cell * 5 / 12
+		length := math.Hypot(to.x - from.x, to.y - from.y)
+		dx, dy := (to.x - from.x) / length, (to.y - from.y) / length
+		base := point{to.x - dx * headLength, to.y - dy * headLength}
+
+		fillPolygon(mask, []point{
+			{from.x + dy * width / 2, from.y - dx * width / 2},
+			{base.x + dy * width / 2, base.y - dx * width / 2},
+			{base.x - dy * width / 2, base.y + dx * width / 2},
+			{from.x - dy * width / 2, from.y + dx * width / 2},
+		})
+		fillPolygon(mask, []point{
+			to,
+			{base.x + dy * headWidth / 2, base.y - dx * headWidth / 2},
+			{base.x - dy * headWidth / 2, base.y + dx * headWidth / 2},
+		})
+	}
+
+	draw.DrawMask(img, img.Bounds(), &image.Uniform{a.Color}, image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// fillPolygon fills a convex polygon, a pixel is inside when its centre is
+func fillPolygon(mask *image.Alpha, points []point) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY = min(minX, p.x), min(minY, p.y)
+		maxX, maxY = max(maxX, p.x), max(maxY, p.y)
+	}
+
+	for y := int(minY); y <= int(maxY); y++ {
+		for x := int(minX); x <= int(maxX); x++ {
+			center := point{float64(x) + 0.5, float64(y) + 0.5}
+			var positive, negative bool
+			for i, p := range points {
+				q := points[(i + 1) % len(points)]
+				cross := (q.x - p.x) * (center.y - p.y) - (q.y - p.y) * (center.x - p.x)
+				positive = positive || cross > 0
+				negative = negative || cross < 0
+			}
+			if !(positive && negative) {
+				mask.SetAlpha(x, y, color.Alpha{0xff})
+			}
+		}
+	}
+}
+
+func fillRing(mask *image.Alpha, center point, inner, outer float64) {
+	for y := int(center.y - outer); y <= int(center.y + outer); y++ {
+		for x := int(center.x - outer); x <= int(center.x + outer); x++ {
+			distance := math.Hypot(float64(x) + 0.5 - center.x, float64(y) + 0.5 - center.y)
+			if distance >= inner && distance <= outer {
+				mask.SetAlpha(x, y, color.Alpha{0xff})
+			}
+		}
+	}
+}
@@ -0,0 +1,599 @@
+package chess2
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SearchOptions struct {
+	Killers bool
+	History bool
+	NullMove bool
+	LateMoveReductions bool
+	PrincipalVariation bool
+	AspirationWindows bool
+	Threads int
+	Ponder bool
+	Clock Clock
+}
+
+func DefaultSearchOptions() SearchOptions {
+	return SearchOptions{
+		Killers: true,
+		History: true,
+		NullMove: true,
+		LateMoveReductions: true,
+		PrincipalVariation: true,
+		AspirationWindows: true,
+		Threads: runtime.NumCPU(),
+		Ponder: true,
+		Clock: SystemClock{},
+	}
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (o SearchOptions) now() time.Time {
+	if o.Clock == nil {
+		return time.Now()
+	}
+	return o.Clock.Now()
+}
+
+// SearchLimits stop the search at whichever limit is hit first; zero means
+// unlimited, though the first iteration always completes
+type SearchLimits struct {
+	Depth int
+	Nodes int64
+	Time time.Duration
+}
+
+// searchControl is shared by all threads of a single search
+type searchControl struct {
+	options SearchOptions
+	limits SearchLimits
+	start time.Time
+	deadline atomic.Int64
+	nodes atomic.Int64
+	// excluded root moves are skipped, to find the next best lines
+	excluded []Move
+}
+
+func newSearchControl(options SearchOptions, limits SearchLimits) *searchControl {
+	result := &searchControl{
+		options: options,
+		limits: limits,
+		start: options.now(),
+	}
+	if limits.Time > 0 {
+		result.setDeadline(result.start.Add(limits.Time))
+	}
+	return result
+}
+
+func (c *searchControl) setDeadline(t time.Time) {
+	c.deadline.Store(t.UnixNano())
+}
+
+func (c *searchControl) expired() bool {
+	deadline := c.deadline.Load()
+	return deadline != 0 && c.options.now().UnixNano() >= deadline
+}
+
+const maxPly = 128
+const infinity = 1000000.
+const mateScore = 1000.
+const mateThreshold = mateScore - maxPly
+const nullWindow = 0.001
+const deltaMargin = 2.0
+const quiescenceEvasions = 2
+const aspirationWindow = 0.5
+const nullMoveReduction = 2
+const maxSearchDepth = 64
+
+type SearchInfo struct {
+	Depth int
+	Score float64
+	// Mate is the number of moves until mate, counted like in chess where the
+	// king is never captured: positive when the side to move wins, negative
+	// when it loses and zero otherwise
+	Mate int
+	Nodes int64
+	Elapsed time.Duration
+	PV []Move
+}
+
+type SearchResult struct {
+	Move Move
+	SearchInfo
+}
+
+type searcher struct {
+	options SearchOptions
+	tt *transpositionTable
+	control *searchControl
+	killers [maxPly][2]Move
+	history [BoardSize * BoardSize][BoardSize * BoardSize]int
+	ctx context.Context
+	canStop bool
+	stopped bool
+	nodes int64
+	rootBest Move
+}
+
+func newSearcher(ctx context.Context, options SearchOptions, tt *transpositionTable, control *searchControl) *searcher {
+	return &searcher{
+		options: options,
+		tt: tt,
+		control: control,
+		ctx: ctx,
+	}
+}
+
+func (s *searcher) countNode() {
+	s.nodes++
+	if s.nodes % 1024 == 0 {
+		s.control.nodes.Add(1024)
+		if s.canStop && (s.ctx.Err() != nil || s.control.expired()) {
+			s.stopped = true
+		}
+	}
+
+	if s.canStop && s.control.limits.Nodes > 0 && s.nodes >= s.control.limits.Nodes {
+		s.stopped = true
+	}
+}
+
+type orderedMove struct {
+	Move
+	isCapture bool
+	see int
+	// order is the move's score in orderMoves, higher goes first
+	order int
+}
+
+func generateMoves(b *Board) []orderedMove {
+	moves := b.AllMoves()
+	result := make([]orderedMove, len(moves))
+	for i, m := range moves {
+		result[i].Move = m
+		if m.IsCapture(b) {
+			result[i].isCapture = true
+			result[i].see = b.SEE(m)
+		}
+	}
+	return result
+}
+
+// score works on a nil searcher, ordering by captures only
+func (s *searcher) score(m orderedMove, ply int, ttMove Move) int {
+	switch {
+	case m.Move == ttMove: return 3000000
+	case ply == 0 && s != nil && m.Move == s.rootBest: return 2000000
+	case m.isCapture && m.see >= 0: return 1000000 + m.see
+	case m.isCapture: return -1000000 + m.see
+	case s == nil: return 0
+	case s.options.Killers && m.Move == s.killers[ply][0]: return 900000
+	case s.options.Killers && m.Move == s.killers[ply][1]: return 800000
+	case s.options.History: return s.history[m.X1 + m.Y1 * BoardSize][m.X2 + m.Y2 * BoardSize]
+	default: return 0
+	}
+}
+
+func (s *searcher) orderMoves(b *Board, ply int, ttMove Move) []orderedMove {
+	result := generateMoves(b)
+	for i := range result {
+		result[i].order = s.score(result[i], ply, ttMove)
+	}
+	slices.SortStableFunc(result, func(a, b orderedMove) int { return b.order - a.order })
+	return result
+}
+
+func (s *searcher) isKiller(m Move, ply int) bool {
+	return s.options.Killers && (m == s.killers[ply][0] || m == s.killers[ply][1])
+}
+
+func (s *searcher) storeCutoff(m Move, depth, ply int) {
+	if s.options.Killers && s.killers[ply][0] != m {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = m
+	}
+
+	if s.options.History {
+		entry := &s.history[m.X1 + m.Y1 * BoardSize][m.X2 + m.Y2 * BoardSize]
+		*entry += depth * depth
+		if *entry > 500000 {
+			for from := range s.history {
+				for to := range s.history[from] {
+					s.history[from][to] /= 2
+				}
+			}
+		}
+	}
+}
+
+// mateIn converts the plies until a king is captured into the usual chess
+// count of moves until the mating move, the capture being the move after it;
+// a king that can be captured right away counts as mate in 1
+func mateIn(score float64) int {
+	switch {
+	case score > mateThreshold: return max(1, (int(math.Round(mateScore - score)) - 1) / 2)
+	case score < -mateThreshold: return -max(1, (int(math.Round(mateScore + score)) - 2) / 2)
+	default: return 0
+	}
+}
+
+// toTT and fromTT convert mate scores between distance from the root and
+// distance from the stored position
+func toTT(score float64, ply int) float64 {
+	switch {
+	case score > mateThreshold: return score + float64(ply)
+	case score < -mateThreshold: return score - float64(ply)
+	default: return score
+	}
+}
+
+func fromTT(score float64, ply int) float64 {
+	switch {
+	case score > mateThreshold: return score - float64(ply)
+	case score < -mateThreshold: return score + float64(ply)
+	default: return score
+	}
+}
+
+func relativeEvaluate(b *Board) float64 {
+	if b.Turn == SideBlack {
+		return -evaluate(b)
+	}
+	return evaluate(b)
+}
+
+func hasPieces(b *Board) bool {
+	for _, piece := range b.inner {
+		if piece.Is(b.Turn) && piece > PieceBlackPawn && piece < PieceWhiteKing {
+			return true
+		}
+	}
+	return false
+}
+
+// quiescence searches captures only, scored for the side to move; evasions is
+// how many more times it may answer a check with quiet moves
+func (s *searcher) quiescence(b *Board, ply, evasions int, alpha, beta float64) float64 {
+	s.countNode()
+	if s.stopped {
+		return 0
+	}
+
+	// the side to move can only lose by having its king captured
+	if b.Winner != SideNone {
+		return -mateScore + float64(ply)
+	}
+
+	if ply >= maxPly - 1 {
+		return relativeEvaluate(b)
+	}
+
+	// the king can be taken, so standing pat is only allowed when it is safe
+	inCheck := evasions > 0 && b.InCheck()
+	standPat := -infinity
+	if !inCheck {
+		standPat = relativeEvaluate(b)
+		if standPat >= beta {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+	}
+
+	bestEval := standPat
+	for _, m := range s.orderMoves(b, maxPly - 1, Move{}) {
+		if !inCheck {
+			if !m.isCapture || m.see < 0 {
+				continue
+			}
+
+			if standPat + float64(m.see) / 100 + deltaMargin < alpha {
+				continue
+			}
+		}
+
+		remaining := evasions
+		if inCheck {
+			remaining--
+		}
+		eval := -s.quiescence(b.Apply(m.Move), ply + 1, remaining, -beta, -alpha)
+		bestEval = max(bestEval, eval)
+		alpha = max(alpha, eval)
+		if beta <= alpha {
+			break
+		}
+	}
+	return bestEval
+}
+
+// negamax scores the position for the side to move
+func (s *searcher) negamax(b *Board, depth, ply int, alpha, beta float64, allowNull bool) float64 {
+	if depth <= 0 || ply >= maxPly - 1 || b.Winner != SideNone {
+		return s.quiescence(b, ply, quiescenceEvasions, alpha, beta)
+	}
+
+	s.countNode()
+	if s.stopped {
+		return 0
+	}
+
+	alpha = max(alpha, -mateScore + float64(ply))
+	beta = min(beta, mateScore - float64(ply + 1))
+	if alpha >= beta {
+		return alpha
+	}
+
+	isPV := beta - alpha > nullWindow
+	hash := b.Hash()
+	var ttMove Move
+	if entry, ok := s.tt.probe(hash); ok {
+		ttMove = entry.move
+		if !isPV && ply > 0 && entry.depth >= depth {
+			score := fromTT(entry.score, ply)
+			switch {
+			case entry.bound == boundExact,
+				entry.bound == boundLower && score >= beta,
+				entry.bound == boundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	inCheck := b.InCheck()
+
+	if s.options.NullMove && allowNull && !isPV && !inCheck && depth >= 3 && hasPieces(b) {
+		nullBoard := *b
+		nullBoard.Turn = 1 - b.Turn
+		nullBoard.LastMove = nil
+		eval := -s.negamax(&nullBoard, depth - 1 - nullMoveReduction, ply + 1, -beta, -beta + nullWindow, false)
+		if s.stopped {
+			return 0
+		}
+		if eval >= beta {
+			return min(eval, mateThreshold)
+		}
+	}
+
+	originalAlpha := alpha
+	bestEval := -infinity
+	var bestMove Move
+	i := -1
+	for _, m := range s.orderMoves(b, ply, ttMove) {
+		if ply == 0 && slices.Contains(s.control.excluded, m.Move) {
+			continue
+		}
+		i++
+		next := b.Apply(m.Move)
+
+		var eval float64
+		if i == 0 {
+			eval = -s.negamax(next, depth - 1, ply + 1, -beta, -alpha, true)
+		} else {
+			reduction := 0
+			if s.options.LateMoveReductions && depth >= 3 && i >= 3 &&
+				!inCheck && !m.isCapture && !s.isKiller(m.Move, ply) {
+				reduction = 1
+				if i >= 8 {
+					reduction = 2
+				}
+			}
+
+			searchBeta := beta
+			if s.options.PrincipalVariation {
+				searchBeta = alpha + nullWindow
+			}
+
+			eval = -s.negamax(next, depth - 1 - reduction, ply + 1, -searchBeta, -alpha, true)
+			if eval > alpha && reduction > 0 {
+				eval = -s.negamax(next, depth - 1, ply + 1, -searchBeta, -alpha, true)
+			}
+			if eval > alpha && eval < beta && searchBeta != beta {
+				eval = -s.negamax(next, depth - 1, ply + 1, -beta, -alpha, true)
+			}
+		}
+
+		if s.stopped {
+			return 0
+		}
+
+		if eval > bestEval {
+			bestEval = eval
+			bestMove = m.Move
+			if ply == 0 {
+				s.rootBest = m.Move
+			}
+		}
+		alpha = max(alpha, eval)
+		if beta <= alpha {
+			if !m.isCapture {
+				s.storeCutoff(m.Move, depth, ply)
+			}
+			break
+		}
+	}
+
+	if bestEval == -infinity {
+		return 0
+	}
+
+	e := ttEntry{move: bestMove, score: toTT(bestEval, ply), depth: depth, bound: boundExact}
+	switch {
+	case bestEval <= originalAlpha: e.bound = boundUpper
+	case bestEval >= beta: e.bound = boundLower
+	}
+	if ply > 0 || len(s.control.excluded) == 0 {
+		s.tt.store(hash, e)
+	}
+
+	return bestEval
+}
+
+// searchRoot runs one iteration of iterative deepening, guessing the window
+// from the previous iteration's score
+func (s *searcher) searchRoot(b *Board, depth int, previous float64) (Move, float64) {
+	if !s.options.AspirationWindows || depth <= 1 {
+		eval := s.negamax(b, depth, 0, -infinity, infinity, false)
+		return s.rootBest, eval
+	}
+
+	window := aspirationWindow
+	alpha := previous - window
+	beta := previous + window
+	for {
+		eval := s.negamax(b, depth, 0, alpha, beta, false)
+		if s.stopped ||
+			eval > alpha && eval < beta ||
+			alpha == -infinity && beta == infinity {
+			return s.rootBest, eval
+		}
+
+		window *= 4
+		if eval <= alpha {
+			alpha = previous - window
+		} else {
+			beta = previous + window
+		}
+
+		if window > 100 {
+			alpha = -infinity
+			beta = infinity
+		}
+	}
+}
+
+func (s *searcher) principalVariation(b *Board, depth int) []Move {
+	pv := []Move{s.rootBest}
+	board := b.Apply(s.rootBest)
+	for len(pv) < depth {
+		entry, ok := s.tt.probe(board.Hash())
+		if !ok || !board.IsMoveLegal(entry.move) {
+			break
+		}
+		pv = append(pv, entry.move)
+		board.Move(entry.move)
+	}
+	return pv
+}
+
+// iterate deepens until stopped, always completing the first iteration so
+// that there is a move to play
+func (s *searcher) iterate(b *Board, firstDepth int, onIteration func(int, float64)) {
+	maxDepth := maxSearchDepth
+	if s.control.limits.Depth > 0 {
+		maxDepth = s.control.limits.Depth
+	}
+
+	var score float64
+	for depth := firstDepth; depth <= maxDepth; depth++ {
+		s.canStop = depth > firstDepth
+		_, eval := s.searchRoot(b, depth, score)
+		if s.stopped {
+			return
+		}
+
+		score = eval
+		if onIteration != nil {
+			onIteration(depth, score)
+		}
+	}
+}
+
+// Search runs a search on a fresh transposition table; with a single thread
+// and a depth or node limit its result is reproducible
+func Search(b *Board, options SearchOptions, limits SearchLimits) SearchResult {
+	control := newSearchControl(options, limits)
+	return search(context.Background(), b, options, newTranspositionTable(), control, nil)
+}
+
+// analyse finds the count best moves one after another, splitting the limits
+// between them
+func analyse(
+	ctx context.Context, b *Board, options SearchOptions,
+	tt *transpositionTable, limits SearchLimits, count int,
+) []SearchResult {
+	limits.Time /= time.Duration(count)
+	limits.Nodes /= int64(count)
+
+	var result []SearchResult
+	var excluded []Move
+	for range count {
+		control := newSearchControl(options, limits)
+		control.excluded = excluded
+		line := search(ctx, b, options, tt, control, nil)
+		if line.Depth == 0 || !b.IsMoveLegal(line.Move) || ctx.Err() != nil {
+			break
+		}
+
+		result = append(result, line)
+		excluded = append(excluded, line.Move)
+	}
+
+	// later lines may be searched deeper and turn out better
+	slices.SortStableFunc(result, func(a, b SearchResult) int { return Sign(b.Score - a.Score) })
+	return result
+}
+
+// search runs Lazy SMP: helper threads search the same position at staggered
+// depths and only communicate with the main thread through the shared table
+func search(
+	ctx context.Context, b *Board, options SearchOptions,
+	tt *transpositionTable, control *searchControl, report func(SearchInfo),
+) SearchResult {
+	// a finished game has no move to search, Depth stays zero
+	if b.Winner != SideNone || len(b.AllMoves()) == 0 {
+		return SearchResult{}
+	}
+
+	helperCtx, stopHelpers := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for i := 1; i < options.Threads; i++ {
+		helper := newSearcher(helperCtx, options, tt, control)
+		// every thread gets its own root, boards are not safe to share
+		root := *b
+		wg.Go(func() {
+			helper.iterate(&root, 1 + i % 2, nil)
+		})
+	}
+
+	var result SearchResult
+	main := newSearcher(ctx, options, tt, control)
+	root := *b
+	main.iterate(&root, 1, func(depth int, score float64) {
+		result = SearchResult{
+			Move: main.rootBest,
+			SearchInfo: SearchInfo{
+				Depth: depth,
+				Score: score,
+				Mate: mateIn(score),
+				Nodes: control.nodes.Load() + main.nodes % 1024,
+				Elapsed: options.now().Sub(control.start),
+				PV: main.principalVariation(b, depth),
+			},
+		}
+		if report != nil {
+			report(result.SearchInfo)
+		}
+	})
+
+	stopHelpers()
+	wg.Wait()
+	return result
+}
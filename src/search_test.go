@@ -0,0 +1,54 @@
+package chess2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name string
+		fen string
+		limits SearchLimits
+		move string
+		score float64
+	}{
+		{"back rank mate", "6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", SearchLimits{Depth: 4}, "a1-a8", 997},
+		{"back rank mate by nodes", "6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", SearchLimits{Nodes: 20000}, "a1-a8", 997},
+		{"rook takes queen", "4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", SearchLimits{Depth: 4}, "d1-d5", 5},
+		{"pawn takes queen", "4k3/8/8/3q4/4P3/8/8/4K3 w - - 0 1", SearchLimits{Depth: 4}, "e4-d5", 1},
+		{"pawn takes queen by nodes", "4k3/8/8/3q4/4P3/8/8/4K3 w - - 0 1", SearchLimits{Nodes: 20000}, "e4-d5", 1},
+	}
+
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = false
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := Search(board, options, test.limits)
+			if result.Move.String() != test.move || math.Abs(result.Score - test.score) > 1e-6 {
+				t.Errorf("got %s with score %v, expected %s with score %v", result.Move, result.Score, test.move, test.score)
+			}
+		})
+	}
+}
+
+func TestMateIn(t *testing.T) {
+	board, err := ParseFEN("6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultSearchOptions()
+	options.Threads = 1
+	options.Ponder = false
+	if result := Search(board, options, SearchLimits{Depth: 4}); result.Mate != 1 {
+		t.Errorf("Ra8# is mate in %d, expected 1", result.Mate)
+	}
+}
@@ -0,0 +1,224 @@
+// Package terminal renders the game with Unicode pieces and ANSI colours and
+// reads moves typed on standard input, for playing where no window can open
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	chess2 "github.com/girvel/chess2/src"
+	"github.com/girvel/chess2/src/frontend"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiClear = "\x1b[H\x1b[2J"
+	ansiWhiteSquare = "\x1b[48;5;180m"
+	ansiBlackSquare = "\x1b[48;5;137m"
+	ansiLastMoveLight = "\x1b[48;5;149m"
+	ansiLastMoveDark = "\x1b[48;5;107m"
+	ansiWhitePiece = "\x1b[1;38;5;231m"
+	ansiBlackPiece = "\x1b[1;38;5;16m"
+	ansiDim = "\x1b[38;5;245m"
+)
+
+// pieceGlyphs are indexed by the piece kind, the colour comes from ANSI codes
+var pieceGlyphs = []rune(" ♟♞♝♜♛♚")
+
+type Terminal struct {
+	out io.Writer
+	lines chan string
+	message string
+	// redraw is set when the screen is out of date
+	redraw bool
+	drawnPly int
+	drawnDepth int
+	drawnHint string
+	// finished is set once the final position has been drawn
+	finished bool
+}
+
+var _ frontend.Frontend = (*Terminal)(nil)
+
+// New starts reading lines from in; the game is drawn to out
+func New(in io.Reader, out io.Writer) *Terminal {
+	t := &Terminal{
+		out: out,
+		lines: make(chan string),
+		redraw: true,
+		drawnPly: -1,
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			t.lines <- scanner.Text()
+		}
+		close(t.lines)
+	}()
+	return t
+}
+
+func pieceString(piece chess2.Piece) string {
+	glyph := string(pieceGlyphs[(piece + 1) / 2])
+	switch piece.Side() {
+	case chess2.SideWhite: return ansiWhitePiece + glyph
+	case chess2.SideBlack: return ansiBlackPiece + glyph
+	default: return " "
+	}
+}
+
+func formatAnalysis(analysis chess2.Analysis) string {
+	if analysis.Depth == 0 {
+		return "AI: waiting"
+	}
+
+	score := fmt.Sprintf("%+.2f", analysis.Score)
+	if analysis.Mate != 0 {
+		score = fmt.Sprintf("mate in %d", analysis.Mate)
+	}
+
+	game := chess2.NewGame(analysis.Root)
+	var pv []string
+	for _, m := range analysis.PV {
+		if !game.Board().IsMoveLegal(m) {
+			break
+		}
+		pv = append(pv, game.Board().SAN(m))
+		game.Play(m)
+	}
+
+	return fmt.Sprintf(
+		"AI: depth %d, %s, %d nodes\npv: %s",
+		analysis.Depth, score, analysis.Nodes, strings.Join(pv, " "),
+	)
+}
+
+func (t *Terminal) Init() {}
+
+func (t *Terminal) Deinit() {}
+
+func formatHints(state *frontend.State) string {
+	var moves []string
+	for _, result := range state.Hints {
+		moves = append(moves, state.Game.Board().SAN(result.Move))
+	}
+	return strings.Join(moves, ", ")
+}
+
+// Draw renders the board when the game, the analysis or the hints have changed
+func (t *Terminal) Draw(state *frontend.State) {
+	game := state.Game
+	analysis := state.Analysis
+	ply := len(game.Moves)
+	hint := formatHints(state)
+	if !t.redraw && ply == t.drawnPly && analysis.Depth == t.drawnDepth && hint == t.drawnHint {
+		return
+	}
+	if hint != "" && hint != t.drawnHint {
+		t.message = ""
+	}
+	t.redraw = false
+	t.drawnPly = ply
+	t.drawnDepth = analysis.Depth
+	t.drawnHint = hint
+	t.finished = game.Result != chess2.ResultNone
+
+	board := game.Board()
+	var result strings.Builder
+	result.WriteString(ansiClear)
+
+	var side []string
+	if ply > 0 {
+		side = append(side, "last move: " + game.MoveNumber(ply - 1) + " " + game.SAN(ply - 1))
+	}
+	side = append(side, strings.Split(formatAnalysis(analysis), "\n")...)
+	if hint != "" {
+		side = append(side, fmt.Sprintf("hint: %s (%d used)", hint, state.HintsUsed))
+	}
+
+	for y := range chess2.BoardSize {
+		fmt.Fprintf(&result, "%s %d %s", ansiDim, chess2.BoardSize - y, ansiReset)
+		for x := range chess2.BoardSize {
+			lastMove := board.LastMove != nil && (
+				x == board.LastMove.X1 && y == board.LastMove.Y1 ||
+				x == board.LastMove.X2 && y == board.LastMove.Y2)
+
+			switch {
+			case (x + y) % 2 == 0 && lastMove: result.WriteString(ansiLastMoveLight)
+			case (x + y) % 2 == 0: result.WriteString(ansiWhiteSquare)
+			case lastMove: result.WriteString(ansiLastMoveDark)
+			default: result.WriteString(ansiBlackSquare)
+			}
+			result.WriteString(" " + pieceString(*board.At(x, y)) + " " + ansiReset)
+		}
+
+		if y < len(side) {
+			result.WriteString("   " + side[y])
+		}
+		result.WriteString("\n")
+	}
+
+	result.WriteString(ansiDim + "   ")
+	for x := range chess2.BoardSize {
+		fmt.Fprintf(&result, " %c ", 'a' + x)
+	}
+	result.WriteString(ansiReset + "\n\n")
+
+	switch {
+	case game.Result != chess2.ResultNone:
+		fmt.Fprintf(&result, "%s (%s)\n", game.Result, game.Termination)
+	case board.Turn == frontend.HumanSide:
+		if t.message != "" {
+			result.WriteString(t.message + "\n")
+		}
+		result.WriteString("your move (e2e4, Nf3, hint or quit): ")
+	default:
+		result.WriteString("AI is thinking...\n")
+	}
+
+	io.WriteString(t.out, result.String())
+}
+
+// ReadInput never blocks, a typed line is handled once it is complete
+func (t *Terminal) ReadInput(state *frontend.State) frontend.Input {
+	game := state.Game
+	var input frontend.Input
+	if t.finished {
+		input.ShouldClose = true
+		return input
+	}
+
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			input.ShouldClose = true
+			return input
+		}
+
+		t.redraw = true
+		t.message = ""
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case line == "quit" || line == "q":
+			input.ShouldClose = true
+		case game.Board().Turn != frontend.HumanSide:
+			t.message = "wait for your turn"
+		case line == "hint" || line == "h":
+			input.Hint = true
+			t.message = "thinking about a hint..."
+		default:
+			move, err := game.Board().ParseAnyMove(line)
+			if err != nil {
+				t.message = err.Error()
+			} else {
+				input.Move = &move
+			}
+		}
+	default:
+	}
+	return input
+}
@@ -0,0 +1,41 @@
+package theme
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ReadAsset reads name from assets, or from the disk when assets is nil; the
+// working directory never replaces an embedded file
+func ReadAsset(assets fs.FS, name string) ([]byte, error) {
+	if assets == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(assets, path.Clean(filepath.ToSlash(name)))
+}
+
+// GlobAssets lists the files matching pattern in assets, or on the disk when
+// assets is nil
+func GlobAssets(assets fs.FS, pattern string) []string {
+	if assets == nil {
+		result, _ := filepath.Glob(pattern)
+		return result
+	}
+
+	result, _ := fs.Glob(assets, filepath.ToSlash(pattern))
+	for i, name := range result {
+		result[i] = filepath.FromSlash(name)
+	}
+	return result
+}
+
+// SpriteAssets is where sprites are read from: the disk when a sprite
+// directory is given explicitly, assets otherwise
+func SpriteAssets(assets fs.FS, spriteDir string) fs.FS {
+	if spriteDir != "" {
+		return nil
+	}
+	return assets
+}
@@ -0,0 +1,115 @@
+// Package theme reads the theme files and assets shared by the window and
+// the image renderer, it doesn't depend on raylib
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Dir holds the theme files, one JSON object per file
+const Dir = "themes"
+
+// Color is a colour written as "#rrggbb" or "#rrggbbaa"
+type Color color.NRGBA
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	hex, ok := strings.CutPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if !ok || len(hex) != 8 || err != nil {
+		return fmt.Errorf("invalid colour %q", s)
+	}
+
+	*c = Color{uint8(value >> 24), uint8(value >> 16), uint8(value >> 8), uint8(value)}
+	return nil
+}
+
+type Theme struct {
+	Name string `json:"name"`
+	// Sprites is the sprite directory; WhiteSprites and BlackSprites optionally
+	// hold separate piece sets, without BlackSprites black pieces are the white
+	// ones recoloured from WhitePiece to BlackPiece
+	Sprites string `json:"sprites"`
+	WhiteSprites string `json:"whiteSprites"`
+	BlackSprites string `json:"blackSprites"`
+
+	WhiteSquare Color `json:"whiteSquare"`
+	BlackSquare Color `json:"blackSquare"`
+	WhitePiece Color `json:"whitePiece"`
+	BlackPiece Color `json:"blackPiece"`
+	Selected Color `json:"selected"`
+	LastMoveLight Color `json:"lastMoveLight"`
+	LastMoveDark Color `json:"lastMoveDark"`
+	Hint Color `json:"hint"`
+	Check Color `json:"check"`
+	Panel Color `json:"panel"`
+	PanelText Color `json:"panelText"`
+}
+
+var Default = Theme{
+	Name: "default",
+	Sprites: "sprites",
+	WhiteSquare: Color{0xed, 0xed, 0xed, 0xff},
+	BlackSquare: Color{0x3a, 0x37, 0x3d, 0xff},
+	WhitePiece: Color{0xed, 0xed, 0xed, 0xff},
+	BlackPiece: Color{0x54, 0x47, 0x47, 0xff},
+	Selected: Color{0xcf, 0xa8, 0x67, 0xff},
+	LastMoveLight: Color{0x86, 0x9d, 0x42, 0xff},
+	LastMoveDark: Color{0x5d, 0x86, 0x3f, 0xff},
+	Hint: Color{0x67, 0xa8, 0xcf, 0xff},
+	Check: Color{0xcf, 0x67, 0x67, 0xff},
+	Panel: Color{0x2b, 0x29, 0x2d, 0xff},
+	PanelText: Color{0xed, 0xed, 0xed, 0xff},
+}
+
+// AnnotationLetters are the colour codes used in PGN [%csl] and [%cal]
+// commands, in the order of AnnotationColors
+const AnnotationLetters = "GRBY"
+
+var AnnotationColors = []Color{
+	{0x5d, 0x86, 0x3f, 0xcc},
+	{0xcf, 0x67, 0x67, 0xcc},
+	{0x67, 0xa8, 0xcf, 0xcc},
+	{0xcf, 0xa8, 0x67, 0xcc},
+}
+
+// Load reads Dir/<name>.json, fields missing from the file keep their
+// default values; the default theme doesn't need a file
+func Load(assets fs.FS, name string) (Theme, error) {
+	result := Default
+	result.Name = name
+	data, err := ReadAsset(assets, filepath.Join(Dir, name + ".json"))
+	if err != nil {
+		if name == Default.Name {
+			return result, nil
+		}
+		return result, fmt.Errorf("theme %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("theme %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// Names lists the themes in Dir on disk and in assets
+func Names(assets fs.FS) []string {
+	var result []string
+	for _, path := range GlobAssets(assets, filepath.Join(Dir, "*.json")) {
+		result = append(result, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package theme
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		json string
+		expected Color
+		ok bool
+	}{
+		{`"#3a373d"`, Color{0x3a, 0x37, 0x3d, 0xff}, true},
+		{`"#5d863fcc"`, Color{0x5d, 0x86, 0x3f, 0xcc}, true},
+		{`"3a373d"`, Color{}, false},
+		{`"#3a37"`, Color{}, false},
+		{`"#zz373d"`, Color{}, false},
+	}
+
+	for _, test := range tests {
+		var c Color
+		err := json.Unmarshal([]byte(test.json), &c)
+		if (err == nil) != test.ok || c != test.expected {
+			t.Errorf("%s: got %v and %v, expected %v", test.json, c, err, test.expected)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	assets := fstest.MapFS{
+		"themes/embedded.json": {Data: []byte(`{"whiteSquare": "#ffffff"}`)},
+	}
+
+	result, err := Load(assets, "embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Name != "embedded" || result.WhiteSquare != (Color{0xff, 0xff, 0xff, 0xff}) || result.BlackSquare != Default.BlackSquare {
+		t.Errorf("got %+v", result)
+	}
+
+	if _, err := Load(assets, "missing"); err == nil {
+		t.Error("expected an error for a missing theme")
+	}
+}
+
+func TestReadAssetIgnoresWorkingDirectory(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir, "default.json"), []byte(`{"whiteSquare": "#000000"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets := fstest.MapFS{"themes/default.json": {Data: []byte(`{}`)}}
+	result, err := Load(assets, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.WhiteSquare != Default.WhiteSquare {
+		t.Error("a theme in the working directory replaced the embedded one")
+	}
+
+	if result, _ := Load(nil, "default"); result.WhiteSquare != (Color{0, 0, 0, 0xff}) {
+		t.Error("without assets the theme should be read from the disk")
+	}
+}
@@ -0,0 +1,122 @@
+package chess2
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+var zobristPieces [PieceBlackKing + 1][BoardSize * BoardSize]uint64
+var zobristCastling [6]uint64
+var zobristEnPassant [BoardSize]uint64
+var zobristTurn uint64
+
+func init() {
+	random := rand.New(rand.NewPCG(2, 0xc4e55))
+	for piece := range zobristPieces {
+		for i := range zobristPieces[piece] {
+			zobristPieces[piece][i] = random.Uint64()
+		}
+	}
+	for i := range zobristCastling {
+		zobristCastling[i] = random.Uint64()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = random.Uint64()
+	}
+	zobristTurn = random.Uint64()
+}
+
+func (b *Board) Hash() uint64 {
+	var result uint64
+	for i, piece := range b.inner {
+		if piece != PieceNone {
+			result ^= zobristPieces[piece][i]
+		}
+	}
+
+	for i, moved := range []bool{b.A1Moved, b.A8Moved, b.E1Moved, b.E8Moved, b.H1Moved, b.H8Moved} {
+		if moved {
+			result ^= zobristCastling[i]
+		}
+	}
+
+	if m := b.LastMove; m != nil && Abs(m.Y2 - m.Y1) == 2 {
+		piece := *b.At(m.X2, m.Y2)
+		if piece == PieceWhitePawn || piece == PieceBlackPawn {
+			result ^= zobristEnPassant[m.X2]
+		}
+	}
+
+	if b.Turn == SideBlack {
+		result ^= zobristTurn
+	}
+	return result
+}
+
+type bound uint64
+const (
+	boundExact bound = iota
+	boundLower
+	boundUpper
+)
+
+type ttEntry struct {
+	move Move
+	score float64
+	depth int
+	bound bound
+}
+
+func (e ttEntry) pack() uint64 {
+	result := uint64(math.Float32bits(float32(e.score)))
+	result |= uint64(e.depth & 0xff) << 32
+	result |= uint64(e.bound) << 40
+	result |= uint64(e.move.X1 | e.move.Y1 << 3 | e.move.X2 << 6 | e.move.Y2 << 9) << 42
+	result |= uint64(e.move.Promotion) << 54
+	return result
+}
+
+func unpackEntry(data uint64) ttEntry {
+	squares := int(data >> 42)
+	move := NewMove(squares & 7, squares >> 3 & 7, squares >> 6 & 7, squares >> 9 & 7)
+	move.Promotion = Piece(data >> 54 & 0xf)
+	return ttEntry{
+		score: float64(math.Float32frombits(uint32(data))),
+		depth: int(data >> 32 & 0xff),
+		bound: bound(data >> 40 & 0b11),
+		move: move,
+	}
+}
+
+// transpositionTable is shared between search threads without locking: each
+// slot stores the key xored with the data, so torn writes fail the probe
+type transpositionTable struct {
+	slots []struct {
+		key, data atomic.Uint64
+	}
+}
+
+const transpositionTableSize = 1 << 20
+
+func newTranspositionTable() *transpositionTable {
+	var result transpositionTable
+	result.slots = make([]struct{ key, data atomic.Uint64 }, transpositionTableSize)
+	return &result
+}
+
+func (tt *transpositionTable) probe(hash uint64) (ttEntry, bool) {
+	slot := &tt.slots[hash % transpositionTableSize]
+	data := slot.data.Load()
+	if slot.key.Load() ^ data != hash {
+		return ttEntry{}, false
+	}
+	return unpackEntry(data), true
+}
+
+func (tt *transpositionTable) store(hash uint64, e ttEntry) {
+	slot := &tt.slots[hash % transpositionTableSize]
+	data := e.pack()
+	slot.key.Store(hash ^ data)
+	slot.data.Store(data)
+}
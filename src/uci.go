@@ -0,0 +1,151 @@
+package chess2
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoMove = errors.New("engine has no move")
+
+// ErrIllegalMove is wrapped by the error for an engine's illegal move, which
+// loses it the game
+var ErrIllegalMove = errors.New("illegal move")
+
+// UCIEngine plays through an external engine speaking the UCI protocol
+type UCIEngine struct {
+	name string
+	cmd *exec.Cmd
+	in io.WriteCloser
+	out *bufio.Scanner
+}
+
+func StartUCIEngine(command string, args ...string) (*UCIEngine, error) {
+	cmd := exec.Command(command, args...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	result := &UCIEngine{
+		name: filepath.Base(command),
+		cmd: cmd,
+		in: in,
+		out: bufio.NewScanner(out),
+	}
+
+	if err := result.send("uci"); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := result.receive()
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := strings.CutPrefix(line, "id name "); ok {
+			result.name = name
+		}
+		if line == "uciok" {
+			break
+		}
+	}
+
+	return result, result.NewGame()
+}
+
+func (e *UCIEngine) send(format string, args ...any) error {
+	_, err := fmt.Fprintf(e.in, format + "\n", args...)
+	return err
+}
+
+func (e *UCIEngine) receive() (string, error) {
+	if !e.out.Scan() {
+		if err := e.out.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s exited", e.name)
+	}
+	return strings.TrimSpace(e.out.Text()), nil
+}
+
+func (e *UCIEngine) waitFor(prefix string) (string, error) {
+	for {
+		line, err := e.receive()
+		if err != nil || strings.HasPrefix(line, prefix) {
+			return line, err
+		}
+	}
+}
+
+func (e *UCIEngine) Name() string {
+	return e.name
+}
+
+func (e *UCIEngine) NewGame() error {
+	if err := e.send("ucinewgame\nisready"); err != nil {
+		return err
+	}
+	_, err := e.waitFor("readyok")
+	return err
+}
+
+func (e *UCIEngine) BestMove(g *Game, limits SearchLimits) (Move, error) {
+	position := "position fen " + g.Start().FEN()
+	if len(g.Moves) > 0 {
+		moves := make([]string, len(g.Moves))
+		for i, m := range g.Moves {
+			moves[i] = m.UCI()
+		}
+		position += " moves " + strings.Join(moves, " ")
+	}
+
+	command := "go"
+	if limits.Depth > 0 {
+		command += fmt.Sprintf(" depth %d", limits.Depth)
+	}
+	if limits.Nodes > 0 {
+		command += fmt.Sprintf(" nodes %d", limits.Nodes)
+	}
+	if limits.Time > 0 {
+		command += fmt.Sprintf(" movetime %d", limits.Time.Milliseconds())
+	}
+	if command == "go" {
+		return Move{}, errors.New("UCI engines need a search limit")
+	}
+
+	if err := e.send("%s\n%s", position, command); err != nil {
+		return Move{}, err
+	}
+
+	line, err := e.waitFor("bestmove")
+	if err != nil {
+		return Move{}, err
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] == "(none)" || fields[1] == "0000" {
+		return Move{}, ErrNoMove
+	}
+	m, err := g.Board().ParseMove(fields[1])
+	if err != nil {
+		return Move{}, fmt.Errorf("%w %s", ErrIllegalMove, fields[1])
+	}
+	return m, nil
+}
+
+func (e *UCIEngine) Close() error {
+	e.send("quit")
+	e.in.Close()
+	return e.cmd.Wait()
+}
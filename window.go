@@ -0,0 +1,42 @@
+//go:build !nogui
+
+package main
+
+import (
+	"flag"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/girvel/chess2/src/frontend"
+	"github.com/girvel/chess2/src/iosystem"
+)
+
+// windowFlags are the options only the raylib window has, builds with the
+// nogui tag leave it out so that they link without cgo and a display
+type windowFlags struct {
+	animation *time.Duration
+	volume *float64
+}
+
+func addWindowFlags(flags *flag.FlagSet) windowFlags {
+	defaults := iosystem.DefaultOptions()
+	return windowFlags{
+		animation: flags.Duration("animation", defaults.AnimationDuration, "duration of move animations, 0 to disable"),
+		volume: flags.Float64("volume", float64(defaults.Volume), "sound volume from 0 to 1"),
+	}
+}
+
+// newWindow creates the window front-end and the function its session logs with
+func (f windowFlags) newWindow(theme, spriteDir string) (frontend.Frontend, func(format string, args ...any), error) {
+	options := iosystem.DefaultOptions()
+	options.AnimationDuration = *f.animation
+	options.Volume = float32(*f.volume)
+	options.Theme = theme
+	options.SpriteDir = spriteDir
+	options.Assets = assets
+
+	logf := func(format string, args ...any) {
+		rl.TraceLog(rl.LogInfo, format, args...)
+	}
+	return iosystem.New(options), logf, nil
+}
@@ -0,0 +1,20 @@
+//go:build nogui
+
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/girvel/chess2/src/frontend"
+)
+
+type windowFlags struct{}
+
+func addWindowFlags(flags *flag.FlagSet) windowFlags {
+	return windowFlags{}
+}
+
+func (windowFlags) newWindow(theme, spriteDir string) (frontend.Frontend, func(format string, args ...any), error) {
+	return nil, nil, errors.New("this build has no window, play with -tui")
+}